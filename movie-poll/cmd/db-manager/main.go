@@ -8,7 +8,9 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/thornzero/movie-poll/models"
 	"github.com/thornzero/movie-poll/services"
+	"github.com/thornzero/movie-poll/types"
 	_ "modernc.org/sqlite"
 )
 
@@ -23,6 +25,11 @@ func main() {
 		fmt.Println("  votes     - List all votes")
 		fmt.Println("  delete-movie <id> - Delete a specific movie")
 		fmt.Println("  delete-votes - Delete all votes")
+		fmt.Println("  history-movie <id> - Show a movie's vote history")
+		fmt.Println("  history-user <name> <device_id> - Show a user's vote history")
+		fmt.Println("  replay-votes - Rebuild the votes table from the vote history")
+		fmt.Println("  rebuild-appeals - Recalculate every appeal score from the votes")
+		fmt.Println("  export-results [csv|json|html] [--votes] [--anonymize] [--round <id>] [--event <id>] - Print the results")
 		os.Exit(1)
 	}
 
@@ -58,6 +65,29 @@ func main() {
 		deleteMovie(id)
 	case "delete-votes":
 		deleteVotes()
+	case "history-movie":
+		if len(os.Args) < 3 {
+			fmt.Println("Usage: history-movie <id>")
+			os.Exit(1)
+		}
+		id, err := strconv.Atoi(os.Args[2])
+		if err != nil {
+			fmt.Printf("Invalid movie ID: %v\n", err)
+			os.Exit(1)
+		}
+		showMovieHistory(id)
+	case "history-user":
+		if len(os.Args) < 4 {
+			fmt.Println("Usage: history-user <name> <device_id>")
+			os.Exit(1)
+		}
+		showUserHistory(os.Args[2], os.Args[3])
+	case "replay-votes":
+		replayVotes()
+	case "rebuild-appeals":
+		rebuildAppeals()
+	case "export-results":
+		exportResults(os.Args[2:])
 	default:
 		fmt.Printf("Unknown command: %s\n", command)
 		os.Exit(1)
@@ -68,7 +98,7 @@ func showStats() {
 	fmt.Println("=== Database Statistics ===")
 
 	// Get voting stats
-	stats, err := services.DB.GetVotingStats()
+	stats, err := services.DB.GetVotingStats(0)
 	if err != nil {
 		log.Printf("Error getting voting stats: %v", err)
 		return
@@ -79,8 +109,8 @@ func showStats() {
 	fmt.Printf("Unique Voters: %d\n", stats.UniqueVoters)
 
 	// Count admin users
-	var adminCount int
-	err = services.DB.QueryRow("SELECT COUNT(*) FROM admin_users").Scan(&adminCount)
+	var adminCount int64
+	err = services.DB.GetDB().Model(&models.AdminUser{}).Count(&adminCount).Error
 	if err != nil {
 		log.Printf("Error counting admin users: %v", err)
 		return
@@ -116,12 +146,12 @@ func cleanDuplicates() {
 	response = strings.TrimSpace(response)
 
 	if strings.ToLower(response) == "y" || strings.ToLower(response) == "yes" {
-		err = services.DB.RemoveDuplicateMovies()
+		removed, err := services.DB.RemoveDuplicateMovies()
 		if err != nil {
 			log.Printf("Error removing duplicates: %v", err)
 			return
 		}
-		fmt.Println("Duplicates removed successfully!")
+		fmt.Printf("Removed %d duplicate movies!\n", removed)
 	} else {
 		fmt.Println("Operation cancelled.")
 	}
@@ -189,12 +219,12 @@ func listVotes() {
 
 func deleteMovie(id int) {
 	// First check if movie exists
-	var title string
-	err := services.DB.QueryRow("SELECT title FROM movies WHERE id = ?", id).Scan(&title)
+	movie, err := services.DB.GetMovieByID(id)
 	if err != nil {
 		fmt.Printf("Movie with ID %d not found\n", id)
 		return
 	}
+	title := movie.Title
 
 	fmt.Printf("Found movie: %s (ID: %d)\n", title, id)
 	fmt.Print("Delete this movie and all its votes? (y/N): ")
@@ -232,8 +262,131 @@ func deleteVotes() {
 	}
 }
 
+func showMovieHistory(id int) {
+	fmt.Printf("=== Vote History for Movie %d ===\n", id)
+
+	revisions, err := services.DB.GetMovieVoteTimeline(uint(id))
+	if err != nil {
+		log.Printf("Error fetching vote history: %v", err)
+		return
+	}
+	printRevisions(revisions)
+}
+
+func showUserHistory(userName, deviceID string) {
+	fmt.Printf("=== Vote History for %s ===\n", userName)
+
+	revisions, err := services.DB.GetUserVoteTimeline(userName, deviceID)
+	if err != nil {
+		log.Printf("Error fetching vote history: %v", err)
+		return
+	}
+	printRevisions(revisions)
+}
+
+func printRevisions(revisions []models.VoteRevision) {
+	if len(revisions) == 0 {
+		fmt.Println("No vote changes recorded")
+		return
+	}
+
+	for _, revision := range revisions {
+		fmt.Printf("%s | Movie ID: %d | %s | %s -> %s | %s\n",
+			revision.CreatedAt.Format("2006-01-02 15:04:05"), revision.MovieID, revision.UserName,
+			formatVoteState(revision.OldVibe, revision.OldSeen),
+			formatVoteState(revision.NewVibe, revision.NewSeen),
+			revision.Source)
+	}
+}
+
+func formatVoteState(vibe *int, seen *bool) string {
+	if vibe == nil {
+		return "none"
+	}
+	seenText := "Not Seen"
+	if seen != nil && *seen {
+		seenText = "Seen"
+	}
+	return fmt.Sprintf("Vibe %d (%s)", *vibe, seenText)
+}
+
+func replayVotes() {
+	fmt.Println("=== Replay Vote History ===")
+	fmt.Print("Rebuild the votes table from the vote history? (y/N): ")
+	reader := bufio.NewReader(os.Stdin)
+	response, _ := reader.ReadString('\n')
+	response = strings.TrimSpace(response)
+
+	if strings.ToLower(response) == "y" || strings.ToLower(response) == "yes" {
+		restored, err := services.DB.ReplayVoteRevisions()
+		if err != nil {
+			log.Printf("Error replaying vote history: %v", err)
+			return
+		}
+		fmt.Printf("Rebuilt votes table with %d votes!\n", restored)
+	} else {
+		fmt.Println("Operation cancelled.")
+	}
+}
+
+func rebuildAppeals() {
+	fmt.Println("=== Rebuild Appeal Scores ===")
+	if err := services.DB.RebuildAppealScores(); err != nil {
+		log.Printf("Error rebuilding appeal scores: %v", err)
+		return
+	}
+	fmt.Printf("Rebuilt appeal scores using the %s strategy!\n", services.DB.GetActiveAppealStrategy().Label())
+}
+
+func exportResults(args []string) {
+	format := services.ExportFormatCSV
+	var options types.ResultsOptions
+	var exportOptions services.ExportOptions
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--votes":
+			exportOptions.IncludeVotes = true
+		case "--anonymize":
+			exportOptions.Anonymize = true
+		case "--round", "--event":
+			if i+1 >= len(args) {
+				fmt.Printf("Usage: %s <id>\n", args[i])
+				os.Exit(1)
+			}
+			id, err := strconv.Atoi(args[i+1])
+			if err != nil || id <= 0 {
+				fmt.Printf("Invalid ID: %s\n", args[i+1])
+				os.Exit(1)
+			}
+			if args[i] == "--round" {
+				options.RoundID = uint(id)
+			} else {
+				options.EventID = uint(id)
+			}
+			i++
+		default:
+			format = args[i]
+		}
+	}
+
+	// Default to the round the results page shows
+	if options.RoundID == 0 {
+		if round, err := services.DB.GetResultsRound(); err == nil && round != nil {
+			options.RoundID = round.ID
+		}
+	}
+
+	export, err := services.DB.GetResultsExport(options, exportOptions)
+	if err != nil {
+		log.Printf("Error building results export: %v", err)
+		return
+	}
+	if err := services.WriteResultsExport(os.Stdout, export, format); err != nil {
+		log.Printf("Error writing results export: %v", err)
+	}
+}
+
 func formatTimestamp(timestamp int64) string {
 	// Simple timestamp formatting - you could use time package for better formatting
 	return fmt.Sprintf("%d", timestamp)
 }
-	
\ No newline at end of file
@@ -6,6 +6,7 @@ require (
 	github.com/a-h/templ v0.3.943
 	github.com/alexedwards/scs/v2 v2.9.0
 	github.com/ryanbradynd05/go-tmdb v0.0.0-20230108222638-2a68dc6ff40c
+	golang.org/x/text v0.29.0
 	gorm.io/driver/postgres v1.6.0
 	gorm.io/driver/sqlite v1.6.0
 	gorm.io/gorm v1.31.0
@@ -28,7 +29,6 @@ require (
 	github.com/mattn/go-sqlite3 v1.14.22 // indirect
 	github.com/rogpeppe/go-internal v1.6.1 // indirect
 	github.com/zeebo/xxh3 v1.0.2 // indirect
-	golang.org/x/text v0.29.0 // indirect
 )
 
 require github.com/joho/godotenv v1.5.1 // direct
@@ -18,6 +18,9 @@ func main() {
 	// Create default admin user if none exists
 	createDefaultAdminUser()
 
+	// Keep a history of appeal rankings
+	services.StartAppealSnapshots()
+
 	// Setup routes using the router service
 	r := services.Router.SetupRoutes()
 
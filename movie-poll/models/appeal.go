@@ -7,11 +7,15 @@ import (
 type Appeal struct {
 	ID              uint      `gorm:"primaryKey" json:"id"`
 	MovieID         uint      `gorm:"not null;index" json:"movie_id"`
+	RoundID         *uint     `gorm:"index" json:"round_id,omitempty"`
+	Strategy        string    `gorm:"not null;default:simple;index" json:"strategy"`
 	AppealScore     float64   `gorm:"not null;index" json:"appeal_score"`
 	TotalVotes      int       `gorm:"not null;default:0" json:"total_votes"`
 	UniqueVoters    int       `gorm:"not null;default:0" json:"unique_voters"`
 	SeenCount       int       `gorm:"not null;default:0" json:"seen_count"`
 	VisibilityRatio float64   `gorm:"not null;default:0" json:"visibility_ratio"`
+	VetoCount       int       `gorm:"not null;default:0" json:"veto_count"`
+	Polarization    float64   `gorm:"not null;default:0;index" json:"polarization"`
 	CalculatedAt    time.Time `json:"calculated_at"`
 
 	// Relationships
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// AppealSnapshot freezes the appeal rankings at a point in time, so a movie's
+// standing can be followed over the days before a screening
+type AppealSnapshot struct {
+	ID       uint      `gorm:"primaryKey" json:"id"`
+	Strategy string    `gorm:"not null;index" json:"strategy"`
+	Reason   string    `gorm:"not null" json:"reason"` // scheduled or manual
+	TakenAt  time.Time `gorm:"not null;index" json:"taken_at"`
+
+	// Relationships
+	Entries []AppealSnapshotEntry `gorm:"foreignKey:SnapshotID;constraint:OnDelete:CASCADE" json:"entries,omitempty"`
+}
+
+// AppealSnapshotEntry is one movie's place in a round at snapshot time
+type AppealSnapshotEntry struct {
+	ID          uint    `gorm:"primaryKey" json:"id"`
+	SnapshotID  uint    `gorm:"not null;index" json:"snapshot_id"`
+	MovieID     uint    `gorm:"not null;index" json:"movie_id"`
+	RoundID     *uint   `gorm:"index" json:"round_id,omitempty"`
+	Rank        int     `gorm:"not null" json:"rank"`
+	AppealScore float64 `gorm:"not null" json:"appeal_score"`
+	TotalVotes  int     `gorm:"not null;default:0" json:"total_votes"`
+}
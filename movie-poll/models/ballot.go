@@ -0,0 +1,42 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// Ballot is one participant's ranked ordering of a round's slate
+type Ballot struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserName  string    `gorm:"not null;index" json:"user_name"`
+	DeviceID  string    `gorm:"not null;index" json:"device_id"`
+	RoundID   *uint     `gorm:"index" json:"round_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relationships
+	Entries []BallotEntry `gorm:"foreignKey:BallotID;constraint:OnDelete:CASCADE" json:"entries,omitempty"`
+}
+
+// BallotEntry is a single preference on a ballot; Rank 1 is the first choice
+type BallotEntry struct {
+	ID       uint `gorm:"primaryKey" json:"id"`
+	BallotID uint `gorm:"not null;index" json:"ballot_id"`
+	MovieID  uint `gorm:"not null;index" json:"movie_id"`
+	Rank     int  `gorm:"not null;check:rank >= 1" json:"rank"`
+}
+
+// RankedMovieIDs returns the ballot's movies in preference order
+func (b *Ballot) RankedMovieIDs() []uint {
+	entries := make([]BallotEntry, len(b.Entries))
+	copy(entries, b.Entries)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Rank < entries[j].Rank
+	})
+
+	ids := make([]uint, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.MovieID
+	}
+	return ids
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+)
+
+// Device is a browser that has used the poll, identified by the device ID
+// kept in its session
+type Device struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	DeviceID  string    `gorm:"uniqueIndex;not null" json:"device_id"`
+	UserAgent string    `json:"user_agent"` // browser family, e.g. "Chrome on Android"
+	LastName  string    `gorm:"index" json:"last_name"`
+	LinkedTo  string    `gorm:"index" json:"linked_to,omitempty"` // device whose votes this one shares, after claiming its pairing code
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+
+	// Relationships
+	Names []DeviceName `gorm:"foreignKey:DeviceID;references:DeviceID" json:"names,omitempty"`
+}
+
+// DeviceName is a name someone has voted under on a device
+type DeviceName struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	DeviceID  string    `gorm:"uniqueIndex:idx_device_name;not null" json:"device_id"`
+	Name      string    `gorm:"uniqueIndex:idx_device_name;not null;index" json:"name"`
+	FirstUsed time.Time `json:"first_used"`
+	LastUsed  time.Time `json:"last_used"`
+}
+
+// DevicePairing is a short-lived code shown on one device that lets another
+// device link to it and vote as the same voter
+type DevicePairing struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	Code      string     `gorm:"index;not null" json:"code"`
+	DeviceID  string     `gorm:"index;not null" json:"device_id"` // device the voter who made the code votes under
+	UserName  string     `gorm:"not null" json:"user_name"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `gorm:"index" json:"expires_at"`
+	ClaimedBy string     `json:"claimed_by,omitempty"` // device that entered the code
+	ClaimedAt *time.Time `json:"claimed_at,omitempty"`
+	MergeID   *uint      `json:"merge_id,omitempty"` // merge of the votes the claiming device already had
+}
+
+// IsClaimed reports whether another device has used the code
+func (p *DevicePairing) IsClaimed() bool {
+	return p.ClaimedAt != nil
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+)
+
+// RSVP statuses
+const (
+	RSVPGoing    = "going"
+	RSVPMaybe    = "maybe"
+	RSVPNotGoing = "not_going"
+)
+
+// Event is a scheduled movie night
+type Event struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	Name          string    `gorm:"not null" json:"name"`
+	StartsAt      time.Time `gorm:"not null;index" json:"starts_at"`
+	Location      string    `json:"location,omitempty"`
+	Capacity      int       `gorm:"not null;default:0" json:"capacity"` // 0 means no limit
+	ChosenMovieID *uint     `json:"chosen_movie_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	// Relationships
+	Movies      []Movie     `gorm:"many2many:event_movies" json:"movies,omitempty"`
+	ChosenMovie *Movie      `gorm:"foreignKey:ChosenMovieID" json:"chosen_movie,omitempty"`
+	RSVPs       []EventRSVP `gorm:"foreignKey:EventID;constraint:OnDelete:CASCADE" json:"rsvps,omitempty"`
+	Screenings  []Screening `gorm:"foreignKey:EventID;constraint:OnDelete:SET NULL" json:"screenings,omitempty"`
+}
+
+// EventRSVP is a participant's answer to an event invitation
+type EventRSVP struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	EventID   uint      `gorm:"not null;uniqueIndex:idx_event_rsvp" json:"event_id"`
+	UserName  string    `gorm:"not null;uniqueIndex:idx_event_rsvp" json:"user_name"`
+	DeviceID  string    `gorm:"not null;uniqueIndex:idx_event_rsvp" json:"device_id"`
+	Status    string    `gorm:"not null;check:status IN ('going','maybe','not_going')" json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName keeps the table name readable
+func (EventRSVP) TableName() string {
+	return "event_rsvps"
+}
+
+// GoingCount returns the number of participants who said they're going
+func (e *Event) GoingCount() int {
+	count := 0
+	for _, rsvp := range e.RSVPs {
+		if rsvp.Status == RSVPGoing {
+			count++
+		}
+	}
+	return count
+}
+
+// IsFull reports whether the event has reached its capacity
+func (e *Event) IsFull() bool {
+	return e.Capacity > 0 && e.GoingCount() >= e.Capacity
+}
+
+// HasMovie reports whether the movie is a candidate for the event
+func (e *Event) HasMovie(movieID uint) bool {
+	for _, movie := range e.Movies {
+		if movie.ID == movieID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"time"
+)
+
+// IdentityMerge records an admin folding several voter identities into one,
+// with enough detail to undo it
+type IdentityMerge struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	CanonicalName string     `gorm:"not null" json:"canonical_name"`
+	DeviceID      string     `gorm:"not null" json:"device_id"` // device the merged votes now belong to
+	AdminUser     string     `json:"admin_user"`
+	CreatedAt     time.Time  `gorm:"index" json:"created_at"`
+	UndoneAt      *time.Time `json:"undone_at,omitempty"`
+
+	// Relationships
+	Votes       []IdentityMergeVote       `gorm:"foreignKey:MergeID" json:"votes,omitempty"`
+	Vetoes      []IdentityMergeVeto       `gorm:"foreignKey:MergeID" json:"vetoes,omitempty"`
+	Ballots     []IdentityMergeBallot     `gorm:"foreignKey:MergeID" json:"ballots,omitempty"`
+	RSVPs       []IdentityMergeRSVP       `gorm:"foreignKey:MergeID" json:"rsvps,omitempty"`
+	Nominations []IdentityMergeNomination `gorm:"foreignKey:MergeID" json:"nominations,omitempty"`
+	Names       []IdentityMergeName       `gorm:"foreignKey:MergeID" json:"names,omitempty"`
+	Links       []IdentityMergeLink       `gorm:"foreignKey:MergeID" json:"links,omitempty"`
+}
+
+// IsUndone reports whether the merge has been reversed
+func (m *IdentityMerge) IsUndone() bool {
+	return m.UndoneAt != nil
+}
+
+// IdentityMergeVote is a vote as it stood before a merge
+type IdentityMergeVote struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	MergeID       uint      `gorm:"not null;index" json:"merge_id"`
+	MovieID       uint      `gorm:"not null" json:"movie_id"`
+	UserName      string    `gorm:"not null" json:"user_name"`
+	DeviceID      string    `gorm:"not null" json:"device_id"`
+	RoundID       *uint     `json:"round_id,omitempty"`
+	Vibe          int       `gorm:"not null" json:"vibe"`
+	Seen          bool      `gorm:"not null" json:"seen"`
+	VoteCreatedAt time.Time `json:"vote_created_at"`
+	VoteUpdatedAt time.Time `json:"vote_updated_at"`
+	Kept          bool      `gorm:"not null" json:"kept"` // carried over to the merged identity rather than dropped
+}
+
+// IdentityMergeVeto is a veto as it stood before a merge
+type IdentityMergeVeto struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	MergeID       uint      `gorm:"not null;index" json:"merge_id"`
+	MovieID       uint      `gorm:"not null" json:"movie_id"`
+	UserName      string    `gorm:"not null" json:"user_name"`
+	DeviceID      string    `gorm:"not null" json:"device_id"`
+	RoundID       *uint     `json:"round_id,omitempty"`
+	Slot          int       `gorm:"not null" json:"slot"`
+	VetoCreatedAt time.Time `json:"veto_created_at"`
+	Kept          bool      `gorm:"not null" json:"kept"`
+}
+
+// IdentityMergeBallot is a ranked ballot as it stood before a merge
+type IdentityMergeBallot struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	MergeID         uint      `gorm:"not null;index" json:"merge_id"`
+	UserName        string    `gorm:"not null" json:"user_name"`
+	DeviceID        string    `gorm:"not null" json:"device_id"`
+	RoundID         *uint     `json:"round_id,omitempty"`
+	Ranking         string    `gorm:"not null" json:"ranking"` // comma-separated movie IDs, first choice first
+	BallotCreatedAt time.Time `json:"ballot_created_at"`
+	BallotUpdatedAt time.Time `json:"ballot_updated_at"`
+	Kept            bool      `gorm:"not null" json:"kept"`
+}
+
+// IdentityMergeRSVP is an RSVP as it stood before a merge
+type IdentityMergeRSVP struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	MergeID       uint      `gorm:"not null;index" json:"merge_id"`
+	EventID       uint      `gorm:"not null" json:"event_id"`
+	UserName      string    `gorm:"not null" json:"user_name"`
+	DeviceID      string    `gorm:"not null" json:"device_id"`
+	Status        string    `gorm:"not null" json:"status"`
+	RSVPCreatedAt time.Time `json:"rsvp_created_at"`
+	RSVPUpdatedAt time.Time `json:"rsvp_updated_at"`
+	Kept          bool      `gorm:"not null" json:"kept"`
+}
+
+// TableName keeps the table name readable
+func (IdentityMergeRSVP) TableName() string {
+	return "identity_merge_rsvps"
+}
+
+// IdentityMergeNomination is a nomination a merge moved to the merged
+// identity, with who made it
+type IdentityMergeNomination struct {
+	ID           uint   `gorm:"primaryKey" json:"id"`
+	MergeID      uint   `gorm:"not null;index" json:"merge_id"`
+	NominationID uint   `gorm:"not null" json:"nomination_id"`
+	UserName     string `gorm:"not null" json:"user_name"`
+	DeviceID     string `gorm:"not null" json:"device_id"`
+}
+
+// IdentityMergeName is a device name a merge changed. Added is true for the
+// canonical name put on a device that didn't have it, and false for an old
+// name the merge removed.
+type IdentityMergeName struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	MergeID     uint   `gorm:"not null;index" json:"merge_id"`
+	DeviceID    string `gorm:"not null" json:"device_id"`
+	Name        string `gorm:"not null" json:"name"`
+	Added       bool   `gorm:"not null" json:"added"`
+	WasLastName bool   `gorm:"not null" json:"was_last_name"` // the device's current name before the merge
+}
+
+// IdentityMergeLink is a device a merge linked to the merged device, with
+// the device it was linked to before, if any
+type IdentityMergeLink struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	MergeID  uint   `gorm:"not null;index" json:"merge_id"`
+	DeviceID string `gorm:"not null" json:"device_id"`
+	LinkedTo string `json:"linked_to,omitempty"`
+}
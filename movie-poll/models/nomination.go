@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+// Nomination statuses
+const (
+	NominationPending  = "pending"
+	NominationApproved = "approved"
+	NominationRejected = "rejected"
+)
+
+// Nomination is a participant's suggestion to add a TMDB movie to the poll.
+// Admins approve or reject it; approval links it to the added movie.
+type Nomination struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	TMDBID     int        `gorm:"not null;index" json:"tmdb_id"`
+	Title      string     `gorm:"not null" json:"title"`
+	Year       *int       `json:"year,omitempty"`
+	PosterPath string     `json:"poster_path,omitempty"`
+	Pitch      string     `json:"pitch,omitempty"`
+	UserName   string     `gorm:"not null;index" json:"user_name"`
+	DeviceID   string     `gorm:"not null" json:"device_id"`
+	Status     string     `gorm:"not null;default:pending;index;check:status IN ('pending','approved','rejected')" json:"status"`
+	MovieID    *uint      `gorm:"index" json:"movie_id,omitempty"`
+	ReviewedBy string     `json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
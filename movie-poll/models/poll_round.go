@@ -0,0 +1,60 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Poll round statuses
+const (
+	PollRoundDraft  = "draft"
+	PollRoundOpen   = "open"
+	PollRoundClosed = "closed"
+)
+
+type PollRound struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	Name      string     `gorm:"not null" json:"name"`
+	Status    string     `gorm:"not null;default:'draft';index" json:"status"`
+	OpensAt   *time.Time `json:"opens_at,omitempty"`
+	ClosesAt  *time.Time `json:"closes_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+
+	// Relationships
+	Movies []Movie `gorm:"many2many:poll_round_movies" json:"movies,omitempty"`
+}
+
+// IsOpenAt reports whether the round accepts votes at the given time
+func (p *PollRound) IsOpenAt(t time.Time) bool {
+	if p.Status != PollRoundOpen {
+		return false
+	}
+	if p.OpensAt != nil && t.Before(*p.OpensAt) {
+		return false
+	}
+	if p.ClosesAt != nil && !t.Before(*p.ClosesAt) {
+		return false
+	}
+	return true
+}
+
+// HasMovie reports whether the movie is on the round's slate
+func (p *PollRound) HasMovie(movieID uint) bool {
+	for _, movie := range p.Movies {
+		if movie.ID == movieID {
+			return true
+		}
+	}
+	return false
+}
+
+// Validation
+func (p *PollRound) BeforeSave(tx *gorm.DB) error {
+	if p.OpensAt != nil && p.ClosesAt != nil && !p.ClosesAt.After(*p.OpensAt) {
+		return errors.New("closes_at must be after opens_at")
+	}
+	return nil
+}
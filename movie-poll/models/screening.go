@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// Screening records a movie being watched at the tavern
+type Screening struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	MovieID    uint      `gorm:"not null;index" json:"movie_id"`
+	EventID    *uint     `gorm:"index" json:"event_id,omitempty"`
+	ScreenedAt time.Time `gorm:"not null;index" json:"screened_at"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	// Relationships
+	Movie     Movie               `gorm:"foreignKey:MovieID;constraint:OnDelete:CASCADE" json:"movie,omitempty"`
+	Attendees []ScreeningAttendee `gorm:"foreignKey:ScreeningID;constraint:OnDelete:CASCADE" json:"attendees,omitempty"`
+}
+
+// ScreeningAttendee is a participant who was in the room for a screening
+type ScreeningAttendee struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	ScreeningID uint   `gorm:"not null;index" json:"screening_id"`
+	UserName    string `gorm:"not null" json:"user_name"`
+	DeviceID    string `gorm:"not null" json:"device_id"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import (
+	"time"
+)
+
+// Setting is an admin-adjustable value that outlives restarts
+type Setting struct {
+	Key       string    `gorm:"primaryKey" json:"key"`
+	Value     string    `gorm:"not null" json:"value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// Veto records a participant spending one of their vetoes to block a movie.
+// Slot numbers the participant's vetoes within a round from 1, so the unique
+// index stops two concurrent casts spending the same veto.
+type Veto struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	MovieID   uint      `gorm:"not null;index" json:"movie_id"`
+	UserName  string    `gorm:"not null;index;uniqueIndex:idx_veto_slot" json:"user_name"`
+	DeviceID  string    `gorm:"not null;index;uniqueIndex:idx_veto_slot" json:"device_id"`
+	RoundID   *uint     `gorm:"index;uniqueIndex:idx_veto_slot" json:"round_id,omitempty"`
+	Slot      int       `gorm:"not null;default:0;uniqueIndex:idx_veto_slot" json:"slot"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relationships
+	Movie Movie `gorm:"foreignKey:MovieID" json:"movie,omitempty"`
+}
@@ -14,6 +14,7 @@ type Vote struct {
 	Vibe      int       `gorm:"not null;check:vibe >= 1 AND vibe <= 6" json:"vibe"`
 	Seen      bool      `gorm:"not null" json:"seen"`
 	DeviceID  string    `gorm:"not null;index" json:"device_id"`
+	RoundID   *uint     `gorm:"index" json:"round_id,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
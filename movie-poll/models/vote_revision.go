@@ -0,0 +1,34 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// VoteRevision is an append-only record of one change to a vote. Old* is nil
+// when the vote was first cast and New* is nil when it was deleted.
+type VoteRevision struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	MovieID   uint      `gorm:"not null;index" json:"movie_id"`
+	UserName  string    `gorm:"not null;index" json:"user_name"`
+	DeviceID  string    `gorm:"not null;index" json:"device_id"`
+	RoundID   *uint     `gorm:"index" json:"round_id,omitempty"`
+	OldVibe   *int      `json:"old_vibe,omitempty"`
+	OldSeen   *bool     `json:"old_seen,omitempty"`
+	NewVibe   *int      `json:"new_vibe,omitempty"`
+	NewSeen   *bool     `json:"new_seen,omitempty"`
+	Source    string    `gorm:"not null" json:"source"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// IsDeletion reports whether the revision removed the vote
+func (r *VoteRevision) IsDeletion() bool {
+	return r.NewVibe == nil
+}
+
+// BeforeUpdate keeps the history append-only
+func (r *VoteRevision) BeforeUpdate(tx *gorm.DB) error {
+	return errors.New("vote revisions are append-only")
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/thornzero/movie-poll/views"
+)
+
+// handleAdminSetAppealStrategy handles choosing how appeal scores are calculated
+func (hr *HandlerRegistry) handleAdminSetAppealStrategy(w http.ResponseWriter, r *http.Request) {
+	// Check if user is logged in as admin
+	sessionData := Session.GetSessionData(r)
+	if sessionData.AdminUser == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+
+	name := r.FormValue("strategy")
+	err := DB.SetActiveAppealStrategy(name)
+	if errors.Is(err, ErrUnknownStrategy) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		LogErrorf("Error setting appeal strategy to %s: %v", name, err)
+		http.Error(w, "Failed to set appeal strategy", http.StatusInternalServerError)
+		return
+	}
+	LogInfof("Admin %s switched appeal strategy to %s", sessionData.AdminUser.Username, name)
+
+	if r.Header.Get("HX-Request") != "true" {
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	views.AppealStrategySection(convertAppealStrategiesToInfo(DB.GetActiveAppealStrategy())).Render(r.Context(), w)
+}
+
+// handleAdminRebuildAppeals handles recalculating every appeal score from
+// scratch, e.g. after changing VETO_PENALTY
+func (hr *HandlerRegistry) handleAdminRebuildAppeals(w http.ResponseWriter, r *http.Request) {
+	// Check if user is logged in as admin
+	sessionData := Session.GetSessionData(r)
+	if sessionData.AdminUser == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := DB.RebuildAppealScores(); err != nil {
+		LogErrorf("Error rebuilding appeal scores: %v", err)
+		http.Error(w, "Failed to rebuild appeal scores", http.StatusInternalServerError)
+		return
+	}
+	LogInfof("Admin %s rebuilt appeal scores", sessionData.AdminUser.Username)
+
+	if r.Header.Get("HX-Request") != "true" {
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	views.AppealStrategySection(convertAppealStrategiesToInfo(DB.GetActiveAppealStrategy())).Render(r.Context(), w)
+}
+
+// convertAppealStrategiesToInfo lists every strategy, flagging the active one
+func convertAppealStrategiesToInfo(active AppealStrategy) []views.AppealStrategyInfo {
+	strategies := AppealStrategies()
+	infos := make([]views.AppealStrategyInfo, len(strategies))
+	for i, strategy := range strategies {
+		infos[i] = convertAppealStrategyToInfo(strategy, strategy.Name() == active.Name())
+	}
+	return infos
+}
+
+// convertAppealStrategyToInfo converts a strategy to its display form
+func convertAppealStrategyToInfo(strategy AppealStrategy, active bool) views.AppealStrategyInfo {
+	return views.AppealStrategyInfo{
+		Name:        strategy.Name(),
+		Label:       strategy.Label(),
+		Description: strategy.Description(),
+		Active:      active,
+	}
+}
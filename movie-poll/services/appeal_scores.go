@@ -0,0 +1,192 @@
+package services
+
+import (
+	"github.com/thornzero/movie-poll/models"
+	"gorm.io/gorm"
+)
+
+// Appeal scores are kept up to date as votes and vetoes change, so reading
+// results never has to recalculate them. The helpers here take the caller's
+// transaction so a score always commits together with the change behind it.
+
+// activeAppealStrategy returns the strategy an admin picked, falling back to
+// APPEAL_STRATEGY and then the simple formula
+func activeAppealStrategy(db *gorm.DB) AppealStrategy {
+	fallback := StrategySimple
+	if Config != nil && Config.AppealStrategy != "" {
+		fallback = Config.AppealStrategy
+	}
+
+	name, err := NewSettingService(db).GetSetting(SettingAppealStrategy, fallback)
+	if err != nil {
+		LogErrorf("Error reading appeal strategy: %v", err)
+	}
+	strategy, err := GetAppealStrategy(name)
+	if err != nil {
+		LogErrorf("Unknown appeal strategy %q, using %s", name, StrategySimple)
+		return SimpleStrategy{}
+	}
+	return strategy
+}
+
+// polarization measures how split the votes are, from 0 when everyone feels
+// the same to 1 when half love it and half are meh. It's the variance of the
+// voters' enthusiasm scaled by the largest variance possible.
+func polarization(votes []models.Vote) float64 {
+	if len(votes) < 2 {
+		return 0
+	}
+
+	mean := 0.0
+	for _, vote := range votes {
+		mean += vibeEnthusiasm(vote.Vibe)
+	}
+	mean /= float64(len(votes))
+
+	variance := 0.0
+	for _, vote := range votes {
+		diff := vibeEnthusiasm(vote.Vibe) - mean
+		variance += diff * diff
+	}
+	variance /= float64(len(votes))
+
+	// Enthusiasm runs 0-1, so the variance tops out at 0.25
+	return variance / 0.25
+}
+
+// scopeListedMovies skips votes left behind by deleted movies
+func scopeListedMovies(db *gorm.DB) *gorm.DB {
+	return db.Where("movie_id IN (SELECT id FROM movies)")
+}
+
+// refreshMovieAppeal recalculates one movie's appeal in a round under the
+// active strategy
+func refreshMovieAppeal(tx *gorm.DB, movieID, roundID uint) error {
+	strategy := activeAppealStrategy(tx)
+
+	err := tx.Where("movie_id = ? AND strategy = ?", movieID, strategy.Name()).
+		Scopes(scopeRound(roundID)).
+		Delete(&models.Appeal{}).Error
+	if err != nil {
+		return err
+	}
+
+	var votes []models.Vote
+	err = tx.Where("movie_id = ?", movieID).Scopes(scopeRound(roundID), scopeListedMovies).
+		Find(&votes).Error
+	if err != nil {
+		return err
+	}
+	if len(votes) == 0 {
+		return nil
+	}
+
+	var vetoCount int64
+	err = tx.Model(&models.Veto{}).Where("movie_id = ?", movieID).
+		Scopes(scopeRound(roundID)).
+		Count(&vetoCount).Error
+	if err != nil {
+		return err
+	}
+
+	appeal := buildAppeal(movieID, votes, int(vetoCount), strategy)
+	appeal.RoundID = roundIDPtr(roundID)
+	return tx.Create(&appeal).Error
+}
+
+// refreshVoteAppeals recalculates the appeal of every movie and round the
+// given votes belong to
+func refreshVoteAppeals(tx *gorm.DB, votes []models.Vote) error {
+	refreshed := make(map[[2]uint]bool)
+	for _, vote := range votes {
+		key := [2]uint{vote.MovieID, derefRoundID(vote.RoundID)}
+		if refreshed[key] {
+			continue
+		}
+		refreshed[key] = true
+		if err := refreshMovieAppeal(tx, key[0], key[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshVetoAppeals recalculates the appeal of each movie and round the
+// vetoes were cast on
+func refreshVetoAppeals(tx *gorm.DB, vetoes []models.Veto) error {
+	votes := make([]models.Vote, len(vetoes))
+	for i, veto := range vetoes {
+		votes[i] = models.Vote{MovieID: veto.MovieID, RoundID: veto.RoundID}
+	}
+	return refreshVoteAppeals(tx, votes)
+}
+
+// rebuildAppeals recalculates every movie's appeal in a round from scratch
+// under the active strategy
+func rebuildAppeals(tx *gorm.DB, roundID uint) error {
+	strategy := activeAppealStrategy(tx)
+
+	// Other strategies' scores stay around for comparison
+	err := tx.Where("strategy = ?", strategy.Name()).
+		Scopes(scopeRound(roundID)).
+		Delete(&models.Appeal{}).Error
+	if err != nil {
+		return err
+	}
+
+	var votes []models.Vote
+	if err := tx.Scopes(scopeRound(roundID), scopeListedMovies).Find(&votes).Error; err != nil {
+		return err
+	}
+	movieVotes := make(map[uint][]models.Vote)
+	var movieIDs []uint
+	for _, vote := range votes {
+		if _, ok := movieVotes[vote.MovieID]; !ok {
+			movieIDs = append(movieIDs, vote.MovieID)
+		}
+		movieVotes[vote.MovieID] = append(movieVotes[vote.MovieID], vote)
+	}
+
+	var vetoes []models.Veto
+	if err := tx.Scopes(scopeRound(roundID)).Find(&vetoes).Error; err != nil {
+		return err
+	}
+	vetoCounts := make(map[uint]int)
+	for _, veto := range vetoes {
+		vetoCounts[veto.MovieID]++
+	}
+
+	for _, movieID := range movieIDs {
+		appeal := buildAppeal(movieID, movieVotes[movieID], vetoCounts[movieID], strategy)
+		appeal.RoundID = roundIDPtr(roundID)
+		if err := tx.Create(&appeal).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebuildAllAppeals recalculates the appeals of every round, plus the votes
+// cast outside any round
+func rebuildAllAppeals(tx *gorm.DB) error {
+	// Clear rounds that no longer have votes too
+	err := tx.Where("strategy = ?", activeAppealStrategy(tx).Name()).Delete(&models.Appeal{}).Error
+	if err != nil {
+		return err
+	}
+
+	var roundIDs []uint
+	err = tx.Model(&models.Vote{}).Where("round_id IS NOT NULL").
+		Distinct("round_id").
+		Pluck("round_id", &roundIDs).Error
+	if err != nil {
+		return err
+	}
+
+	for _, roundID := range append([]uint{0}, roundIDs...) {
+		if err := rebuildAppeals(tx, roundID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/thornzero/movie-poll/models"
+)
+
+var ErrUnknownStrategy = errors.New("unknown appeal strategy")
+
+// Appeal strategy names, as stored on each appeal row
+const (
+	StrategySimple           = "simple"
+	StrategyVibeWeighted     = "vibe_weighted"
+	StrategyBayesian         = "bayesian"
+	StrategySharedExperience = "shared_experience"
+)
+
+// AppealStrategy turns a movie's votes into an appeal score. Vetoes are
+// applied afterwards, the same way for every strategy.
+type AppealStrategy interface {
+	Name() string
+	Label() string
+	Description() string
+	Score(votes []models.Vote) float64
+}
+
+// AppealStrategies lists every strategy an admin can choose from
+func AppealStrategies() []AppealStrategy {
+	priorMean, priorWeight := 50, 3
+	if Config != nil {
+		priorMean, priorWeight = Config.AppealPriorMean, Config.AppealPriorWeight
+	}
+
+	return []AppealStrategy{
+		SimpleStrategy{},
+		VibeWeightedStrategy{},
+		BayesianStrategy{
+			PriorMean:   float64(priorMean) / 100,
+			PriorWeight: float64(priorWeight),
+		},
+		SharedExperienceStrategy{},
+	}
+}
+
+// GetAppealStrategy looks up a strategy by name
+func GetAppealStrategy(name string) (AppealStrategy, error) {
+	for _, strategy := range AppealStrategies() {
+		if strategy.Name() == name {
+			return strategy, nil
+		}
+	}
+	return nil, ErrUnknownStrategy
+}
+
+// vibeEnthusiasm maps a vibe onto 0-1, where 1 is Rewatch/Stoked and 0 is
+// Meh/Later
+func vibeEnthusiasm(vibe int) float64 {
+	switch {
+	case vibe <= 1:
+		return 1
+	case vibe >= 3:
+		return 0
+	default:
+		return 0.5
+	}
+}
+
+// SimpleStrategy is the original formula: lots of votes from lots of people
+// who mostly haven't seen it
+type SimpleStrategy struct{}
+
+func (SimpleStrategy) Name() string  { return StrategySimple }
+func (SimpleStrategy) Label() string { return "Simple" }
+func (SimpleStrategy) Description() string {
+	return "Votes × voters ÷ (seen + 1). Ignores how people feel about the movie."
+}
+
+func (SimpleStrategy) Score(votes []models.Vote) float64 {
+	uniqueVoters := make(map[string]bool)
+	seenCount := 0
+	for _, vote := range votes {
+		uniqueVoters[vote.UserName] = true
+		if vote.Seen {
+			seenCount++
+		}
+	}
+	return float64(len(votes)) * float64(len(uniqueVoters)) / float64(seenCount+1)
+}
+
+// VibeWeightedStrategy averages how enthusiastic voters are, on a 0-10 scale
+type VibeWeightedStrategy struct{}
+
+func (VibeWeightedStrategy) Name() string  { return StrategyVibeWeighted }
+func (VibeWeightedStrategy) Label() string { return "Vibe-weighted" }
+func (VibeWeightedStrategy) Description() string {
+	return "Average enthusiasm of everyone who voted, from 0 (all Meh/Later) to 10 (all Rewatch/Stoked)."
+}
+
+func (VibeWeightedStrategy) Score(votes []models.Vote) float64 {
+	if len(votes) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, vote := range votes {
+		total += vibeEnthusiasm(vote.Vibe)
+	}
+	return 10 * total / float64(len(votes))
+}
+
+// BayesianStrategy averages enthusiasm like VibeWeightedStrategy, but starts
+// every movie with PriorWeight imaginary votes at PriorMean so a single
+// excited voter can't top the list
+type BayesianStrategy struct {
+	PriorMean   float64 // 0-1
+	PriorWeight float64 // number of imaginary votes
+}
+
+func (BayesianStrategy) Name() string  { return StrategyBayesian }
+func (BayesianStrategy) Label() string { return "Bayesian average" }
+func (BayesianStrategy) Description() string {
+	return "Average enthusiasm pulled toward a prior, so movies need several votes before they can rank high."
+}
+
+func (s BayesianStrategy) Score(votes []models.Vote) float64 {
+	total := s.PriorMean * s.PriorWeight
+	for _, vote := range votes {
+		total += vibeEnthusiasm(vote.Vibe)
+	}
+	count := s.PriorWeight + float64(len(votes))
+	if count == 0 {
+		return 0
+	}
+	return 10 * total / count
+}
+
+// SharedExperienceStrategy rewards movies that many voters haven't seen and
+// are excited about, so the group discovers it together
+type SharedExperienceStrategy struct{}
+
+func (SharedExperienceStrategy) Name() string  { return StrategySharedExperience }
+func (SharedExperienceStrategy) Label() string { return "Shared new experience" }
+func (SharedExperienceStrategy) Description() string {
+	return "Share of voters who haven't seen it and want to, on a 0-10 scale. Rewatch votes don't count."
+}
+
+func (SharedExperienceStrategy) Score(votes []models.Vote) float64 {
+	if len(votes) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, vote := range votes {
+		if !vote.Seen {
+			total += vibeEnthusiasm(vote.Vibe)
+		}
+	}
+	return 10 * total / float64(len(votes))
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thornzero/movie-poll/views"
+)
+
+// handleRankedBallot handles the ranked-choice ballot page
+func (hr *HandlerRegistry) handleRankedBallot(w http.ResponseWriter, r *http.Request) {
+	sessionData := Session.GetSessionData(r)
+	if sessionData.UserName == "" {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	round, err := DB.GetCurrentRound()
+	if err != nil {
+		LogErrorf("Error fetching current round: %v", err)
+		http.Error(w, "Failed to load ballot", http.StatusInternalServerError)
+		return
+	}
+	var roundID uint
+	roundName := ""
+	if round != nil {
+		roundID = round.ID
+		roundName = round.Name
+	}
+
+	movies, err := getVotingSequence(sessionData)
+	if errors.Is(err, ErrNoOpenRound) {
+		views.NoOpenRoundPage(sessionData.UserName).Render(r.Context(), w)
+		return
+	}
+	if err != nil {
+		LogErrorf("Error fetching movies: %v", err)
+		http.Error(w, "Failed to load movies", http.StatusInternalServerError)
+		return
+	}
+
+	ranking, err := DB.GetBallot(sessionData.UserName, sessionData.VoterDeviceID(), roundID)
+	if err != nil {
+		LogErrorf("Error loading ballot for %s: %v", sessionData.UserName, err)
+		http.Error(w, "Failed to load ballot", http.StatusInternalServerError)
+		return
+	}
+
+	ranks := make(map[int]int, len(ranking))
+	for i, movieID := range ranking {
+		ranks[int(movieID)] = i + 1
+	}
+
+	movieInfos := make([]views.MovieInfo, len(movies))
+	for i, movie := range movies {
+		year := 0
+		if movie.Year != nil {
+			year = *movie.Year
+		}
+		movieInfos[i] = views.MovieInfo{
+			ID:      movie.ID,
+			Title:   movie.Title,
+			Year:    year,
+			AddedAt: time.Unix(movie.AddedAt, 0),
+		}
+	}
+
+	ballotData := views.RankedBallotData{
+		UserName:  sessionData.UserName,
+		RoundName: roundName,
+		Movies:    movieInfos,
+		Ranks:     ranks,
+	}
+
+	views.RankedBallotPage(ballotData).Render(r.Context(), w)
+}
+
+// handleSubmitRankedBallot handles saving a ranked-choice ballot. The form
+// carries one rank_<movieID> field per movie; blank fields are unranked.
+func (hr *HandlerRegistry) handleSubmitRankedBallot(w http.ResponseWriter, r *http.Request) {
+	sessionData := Session.GetSessionData(r)
+	if sessionData.UserName == "" {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	movieIDs, err := parseBallotRanks(r.PostForm)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = DB.SubmitBallot(sessionData.UserName, sessionData.VoterDeviceID(), movieIDs)
+	if errors.Is(err, ErrEmptyBallot) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		writeVoteError(w, err)
+		return
+	}
+
+	if r.Header.Get("HX-Request") != "true" {
+		http.Redirect(w, r, "/results", http.StatusSeeOther)
+		return
+	}
+	views.BallotSaved(len(movieIDs)).Render(r.Context(), w)
+}
+
+// parseBallotRanks turns rank_<movieID> form fields into movie IDs in
+// preference order. Ranks don't need to be contiguous but must be unique.
+func parseBallotRanks(form map[string][]string) ([]uint, error) {
+	type rankedMovie struct {
+		movieID uint
+		rank    int
+	}
+
+	var ranked []rankedMovie
+	seen := make(map[int]bool)
+	for key, values := range form {
+		idStr, ok := strings.CutPrefix(key, "rank_")
+		if !ok || len(values) == 0 || values[0] == "" {
+			continue
+		}
+
+		movieID, err := strconv.Atoi(idStr)
+		if err != nil || movieID <= 0 {
+			return nil, errors.New("invalid movie ID")
+		}
+		rank, err := strconv.Atoi(values[0])
+		if err != nil || rank < 1 {
+			return nil, errors.New("invalid rank")
+		}
+		if seen[rank] {
+			return nil, errors.New("each rank can only be used once")
+		}
+		seen[rank] = true
+
+		ranked = append(ranked, rankedMovie{movieID: uint(movieID), rank: rank})
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		return ranked[i].rank < ranked[j].rank
+	})
+
+	movieIDs := make([]uint, len(ranked))
+	for i, entry := range ranked {
+		movieIDs[i] = entry.movieID
+	}
+	return movieIDs, nil
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/thornzero/movie-poll/models"
+	"github.com/thornzero/movie-poll/types"
+	"gorm.io/gorm"
+)
+
+var ErrEmptyBallot = errors.New("ballot must rank at least one movie")
+
+type BallotService struct {
+	db     *gorm.DB
+	rounds *RoundService
+}
+
+func NewBallotService(db *gorm.DB, rounds *RoundService) *BallotService {
+	return &BallotService{db: db, rounds: rounds}
+}
+
+// SubmitBallot replaces the participant's ranked ballot for the current round.
+// movieIDs are in preference order, first choice first.
+func (s *BallotService) SubmitBallot(userName, deviceID string, movieIDs []uint) error {
+	if len(movieIDs) == 0 {
+		return ErrEmptyBallot
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// Every ranked movie must be votable in the same round
+		var roundID *uint
+		for _, movieID := range movieIDs {
+			id, err := NewRoundService(tx).ResolveVoteRound(0, movieID)
+			if err != nil {
+				return err
+			}
+			roundID = id
+		}
+
+		var existing []models.Ballot
+		err := tx.Where("user_name = ? AND device_id = ?", userName, deviceID).
+			Scopes(scopeRound(derefRoundID(roundID))).
+			Find(&existing).Error
+		if err != nil {
+			return err
+		}
+		for _, ballot := range existing {
+			if err := tx.Where("ballot_id = ?", ballot.ID).Delete(&models.BallotEntry{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&ballot).Error; err != nil {
+				return err
+			}
+		}
+
+		ballot := models.Ballot{
+			UserName: userName,
+			DeviceID: deviceID,
+			RoundID:  roundID,
+		}
+		for i, movieID := range movieIDs {
+			ballot.Entries = append(ballot.Entries, models.BallotEntry{
+				MovieID: movieID,
+				Rank:    i + 1,
+			})
+		}
+		return tx.Create(&ballot).Error
+	})
+}
+
+// GetBallot returns the participant's ranking for a round, or nil if they
+// haven't submitted one
+func (s *BallotService) GetBallot(userName, deviceID string, roundID uint) ([]uint, error) {
+	var ballot models.Ballot
+	err := s.db.Preload("Entries").
+		Where("user_name = ? AND device_id = ?", userName, deviceID).
+		Scopes(scopeRound(roundID)).
+		First(&ballot).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ballot.RankedMovieIDs(), nil
+}
+
+// TallyInstantRunoff counts the round's ballots by instant-runoff voting
+func (s *BallotService) TallyInstantRunoff(roundID uint) (*types.RunoffResult, error) {
+	var ballots []models.Ballot
+	err := s.db.Preload("Entries").Scopes(scopeRound(roundID)).Find(&ballots).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Candidates are the round's slate, or every ranked movie when unscoped
+	var candidates []models.Movie
+	if roundID > 0 {
+		round, err := s.rounds.GetRound(roundID)
+		if err != nil {
+			return nil, err
+		}
+		candidates = round.Movies
+	} else {
+		err = s.db.Where(`id IN (SELECT DISTINCT ballot_entries.movie_id FROM ballot_entries
+			JOIN ballots ON ballots.id = ballot_entries.ballot_id WHERE ballots.round_id IS NULL)`).
+			Find(&candidates).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	titles := make(map[uint]string, len(candidates))
+	for _, movie := range candidates {
+		titles[movie.ID] = movie.Title
+	}
+
+	rankings := make([][]uint, len(ballots))
+	for i := range ballots {
+		rankings[i] = ballots[i].RankedMovieIDs()
+	}
+
+	return instantRunoff(rankings, titles), nil
+}
+
+// instantRunoff repeatedly counts each ballot towards its highest-ranked
+// continuing candidate and eliminates the weakest candidates until one holds
+// a majority of the ballots still in play
+func instantRunoff(rankings [][]uint, titles map[uint]string) *types.RunoffResult {
+	result := &types.RunoffResult{TotalBallots: len(rankings)}
+	if len(rankings) == 0 || len(titles) == 0 {
+		return result
+	}
+
+	continuing := make(map[uint]bool, len(titles))
+	for id := range titles {
+		continuing[id] = true
+	}
+
+	for number := 1; len(continuing) > 0; number++ {
+		votes := make(map[uint]int, len(continuing))
+		exhausted := 0
+		for _, ranking := range rankings {
+			counted := false
+			for _, movieID := range ranking {
+				if continuing[movieID] {
+					votes[movieID]++
+					counted = true
+					break
+				}
+			}
+			if !counted {
+				exhausted++
+			}
+		}
+
+		round := types.RunoffRound{Number: number, Exhausted: exhausted}
+		for id := range continuing {
+			round.Counts = append(round.Counts, types.RunoffCount{
+				MovieID: int(id),
+				Title:   titles[id],
+				Votes:   votes[id],
+			})
+		}
+		sortRunoffCounts(round.Counts)
+
+		active := len(rankings) - exhausted
+		leader := round.Counts[0]
+		if len(round.Counts) == 1 || (active > 0 && leader.Votes*2 > active) {
+			result.Rounds = append(result.Rounds, round)
+			result.Winners = []types.RunoffCount{leader}
+			break
+		}
+
+		// Eliminate everyone tied for last; if that's everyone, it's a tie
+		lowest := round.Counts[len(round.Counts)-1].Votes
+		for _, count := range round.Counts {
+			if count.Votes == lowest {
+				round.Eliminated = append(round.Eliminated, count)
+			}
+		}
+		if len(round.Eliminated) == len(round.Counts) {
+			round.Eliminated = nil
+			result.Rounds = append(result.Rounds, round)
+			result.Winners = round.Counts
+			break
+		}
+
+		for _, count := range round.Eliminated {
+			delete(continuing, uint(count.MovieID))
+		}
+		result.Rounds = append(result.Rounds, round)
+	}
+
+	return result
+}
+
+// sortRunoffCounts orders counts by votes, most first, then by title
+func sortRunoffCounts(counts []types.RunoffCount) {
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Votes != counts[j].Votes {
+			return counts[i].Votes > counts[j].Votes
+		}
+		return counts[i].Title < counts[j].Title
+	})
+}
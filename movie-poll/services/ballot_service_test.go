@@ -0,0 +1,140 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thornzero/movie-poll/types"
+)
+
+func TestInstantRunoff(t *testing.T) {
+	titles := map[uint]string{1: "Alien", 2: "Brazil", 3: "Clue", 4: "Dune"}
+	without := func(ids ...uint) map[uint]string {
+		trimmed := make(map[uint]string, len(titles))
+		for id, title := range titles {
+			trimmed[id] = title
+		}
+		for _, id := range ids {
+			delete(trimmed, id)
+		}
+		return trimmed
+	}
+	repeat := func(ranking []uint, n int) [][]uint {
+		rankings := make([][]uint, n)
+		for i := range rankings {
+			rankings[i] = ranking
+		}
+		return rankings
+	}
+	concat := func(groups ...[][]uint) [][]uint {
+		var rankings [][]uint
+		for _, group := range groups {
+			rankings = append(rankings, group...)
+		}
+		return rankings
+	}
+
+	tests := []struct {
+		name       string
+		rankings   [][]uint
+		titles     map[uint]string
+		winners    []int   // movie IDs
+		eliminated [][]int // movie IDs knocked out in each round
+		exhausted  []int   // ballots out of choices in each round
+	}{
+		{
+			name: "first round majority",
+			rankings: concat(
+				repeat([]uint{1, 2}, 3),
+				[][]uint{{2, 1}, {3}},
+			),
+			titles:     titles,
+			winners:    []int{1},
+			eliminated: [][]int{nil},
+			exhausted:  []int{0},
+		},
+		{
+			name: "transfer decides a later round",
+			rankings: concat(
+				repeat([]uint{1}, 3),
+				repeat([]uint{2}, 3),
+				[][]uint{{3, 2}},
+			),
+			titles:     without(4),
+			winners:    []int{2},
+			eliminated: [][]int{{3}, nil},
+			exhausted:  []int{0, 0},
+		},
+		{
+			name: "tie for last eliminates both",
+			rankings: concat(
+				repeat([]uint{1}, 3),
+				repeat([]uint{4}, 2),
+				[][]uint{{2, 4}, {3, 4}},
+			),
+			titles:     titles,
+			winners:    []int{4},
+			eliminated: [][]int{{2, 3}, nil},
+			exhausted:  []int{0, 0},
+		},
+		{
+			name:       "tie between everyone left",
+			rankings:   [][]uint{{1}, {2}},
+			titles:     without(3, 4),
+			winners:    []int{1, 2},
+			eliminated: [][]int{nil},
+			exhausted:  []int{0},
+		},
+		{
+			name: "ballots run out of choices",
+			rankings: concat(
+				repeat([]uint{1}, 3),
+				repeat([]uint{2}, 2),
+				[][]uint{{3}},
+			),
+			titles:     without(4),
+			winners:    []int{1},
+			eliminated: [][]int{{3}, nil},
+			exhausted:  []int{0, 1},
+		},
+		{
+			name:   "no ballots",
+			titles: titles,
+		},
+	}
+
+	ids := func(counts []types.RunoffCount) []int {
+		var movieIDs []int
+		for _, count := range counts {
+			movieIDs = append(movieIDs, count.MovieID)
+		}
+		return movieIDs
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := instantRunoff(tt.rankings, tt.titles)
+
+			if result.TotalBallots != len(tt.rankings) {
+				t.Errorf("TotalBallots = %d, want %d", result.TotalBallots, len(tt.rankings))
+			}
+			if got := ids(result.Winners); !reflect.DeepEqual(got, tt.winners) {
+				t.Errorf("winners = %v, want %v", got, tt.winners)
+			}
+			if len(result.Rounds) != len(tt.eliminated) {
+				t.Fatalf("got %d rounds, want %d", len(result.Rounds), len(tt.eliminated))
+			}
+			for i, round := range result.Rounds {
+				if round.Number != i+1 {
+					t.Errorf("round %d numbered %d", i+1, round.Number)
+				}
+				if got := ids(round.Eliminated); !reflect.DeepEqual(got, tt.eliminated[i]) {
+					t.Errorf("round %d eliminated %v, want %v", i+1, got, tt.eliminated[i])
+				}
+				if round.Exhausted != tt.exhausted[i] {
+					t.Errorf("round %d exhausted = %d, want %d", i+1, round.Exhausted, tt.exhausted[i])
+				}
+			}
+		})
+	}
+}
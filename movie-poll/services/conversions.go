@@ -63,7 +63,7 @@ func convertTypeMovieToGORM(typeMovie *types.Movie) models.Movie {
 }
 
 func convertGORMVoteToType(gormVote models.Vote) types.Vote {
-	return types.Vote{
+	vote := types.Vote{
 		ID:        int(gormVote.ID),
 		MovieID:   int(gormVote.MovieID),
 		UserName:  gormVote.UserName,
@@ -73,6 +73,10 @@ func convertGORMVoteToType(gormVote models.Vote) types.Vote {
 		CreatedAt: gormVote.CreatedAt.Unix(),
 		UpdatedAt: gormVote.UpdatedAt.Unix(),
 	}
+	if gormVote.RoundID != nil {
+		vote.RoundID = int(*gormVote.RoundID)
+	}
+	return vote
 }
 
 func convertTypeVoteToGORM(typeVote *types.Vote) models.Vote {
@@ -85,6 +89,11 @@ func convertTypeVoteToGORM(typeVote *types.Vote) models.Vote {
 		DeviceID: typeVote.DeviceID,
 	}
 
+	if typeVote.RoundID > 0 {
+		roundID := uint(typeVote.RoundID)
+		gormVote.RoundID = &roundID
+	}
+
 	// Set timestamps if they exist
 	if typeVote.CreatedAt > 0 {
 		gormVote.CreatedAt = time.Unix(typeVote.CreatedAt, 0)
@@ -95,3 +104,26 @@ func convertTypeVoteToGORM(typeVote *types.Vote) models.Vote {
 
 	return gormVote
 }
+
+// convertAppealToSummary converts an appeal with its movie loaded to a results row
+func convertAppealToSummary(appeal models.Appeal) types.VotingSummary {
+	return types.VotingSummary{
+		MovieID:         int(appeal.Movie.ID),
+		Title:           appeal.Movie.Title,
+		Year:            appeal.Movie.Year,
+		Overview:        appeal.Movie.Overview,
+		PosterPath:      appeal.Movie.PosterPath,
+		ReleaseDate:     appeal.Movie.ReleaseDate,
+		VoteCount:       appeal.TotalVotes,
+		AppealScore:     appeal.AppealScore,
+		Strategy:        appeal.Strategy,
+		SeenCount:       appeal.SeenCount,
+		NotSeenCount:    appeal.TotalVotes - appeal.SeenCount,
+		VisibilityRatio: appeal.VisibilityRatio,
+		TotalVotes:      appeal.TotalVotes,
+		UniqueVoters:    appeal.UniqueVoters,
+		VetoCount:       appeal.VetoCount,
+		Polarization:    appeal.Polarization,
+		CalculatedAt:    appeal.CalculatedAt.Unix(),
+	}
+}
@@ -50,7 +50,8 @@ func NewDatabase(config DatabaseConfig) (*gorm.DB, error) {
 	}
 
 	return gorm.Open(dialector, &gorm.Config{
-		Logger: logger.Default.LogMode(config.LogLevel),
+		Logger:         logger.Default.LogMode(config.LogLevel),
+		TranslateError: true,
 	})
 }
 
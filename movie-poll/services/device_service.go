@@ -0,0 +1,298 @@
+package services
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thornzero/movie-poll/models"
+	"github.com/thornzero/movie-poll/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// deviceTouchInterval limits how often a busy device's last-seen time is
+// written back
+const deviceTouchInterval = time.Minute
+
+type DeviceService struct {
+	db *gorm.DB
+
+	mu        sync.Mutex
+	touched   map[string]time.Time // device ID -> last write of its last-seen time
+	lastSweep time.Time
+}
+
+func NewDeviceService(db *gorm.DB) *DeviceService {
+	return &DeviceService{db: db, touched: make(map[string]time.Time)}
+}
+
+// TouchDevice registers a device or refreshes when it was last seen and
+// with which browser
+func (s *DeviceService) TouchDevice(deviceID, userAgent string) error {
+	now := time.Now()
+	s.mu.Lock()
+	if now.Sub(s.touched[deviceID]) < deviceTouchInterval {
+		s.mu.Unlock()
+		return nil
+	}
+	s.touched[deviceID] = now
+	// Entries older than the interval no longer hold anything back, so
+	// they're swept out once an interval to keep the map to recent devices
+	if now.Sub(s.lastSweep) >= deviceTouchInterval {
+		for id, touchedAt := range s.touched {
+			if now.Sub(touchedAt) >= deviceTouchInterval {
+				delete(s.touched, id)
+			}
+		}
+		s.lastSweep = now
+	}
+	s.mu.Unlock()
+
+	device := models.Device{
+		DeviceID:  deviceID,
+		UserAgent: userAgentFamily(userAgent),
+		FirstSeen: now,
+		LastSeen:  now,
+	}
+	updates := []string{"last_seen"}
+	if device.UserAgent != "" {
+		updates = append(updates, "user_agent")
+	}
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "device_id"}},
+		DoUpdates: clause.AssignmentColumns(updates),
+	}).Create(&device).Error
+}
+
+// RecordName notes that a device has been used under a name, making it the
+// device's current name
+func (s *DeviceService) RecordName(deviceID, name string) error {
+	now := time.Now()
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		device := models.Device{
+			DeviceID:  deviceID,
+			LastName:  name,
+			FirstSeen: now,
+			LastSeen:  now,
+		}
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "device_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"last_name", "last_seen"}),
+		}).Create(&device).Error
+		if err != nil {
+			return err
+		}
+
+		deviceName := models.DeviceName{
+			DeviceID:  deviceID,
+			Name:      name,
+			FirstUsed: now,
+			LastUsed:  now,
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "device_id"}, {Name: "name"}},
+			DoUpdates: clause.AssignmentColumns([]string{"last_used"}),
+		}).Create(&deviceName).Error
+	})
+}
+
+// GetNames lists the names used on a device, most recent first
+func (s *DeviceService) GetNames(deviceID string) ([]string, error) {
+	var names []string
+	err := s.db.Model(&models.DeviceName{}).
+		Where("device_id = ?", deviceID).
+		Order("last_used DESC").
+		Pluck("name", &names).Error
+	return names, err
+}
+
+// GetLastName returns the name a device last used, or "" if it has none
+func (s *DeviceService) GetLastName(deviceID string) (string, error) {
+	var devices []models.Device
+	err := s.db.Where("device_id = ?", deviceID).Limit(1).Find(&devices).Error
+	if err != nil || len(devices) == 0 {
+		return "", err
+	}
+	return devices[0].LastName, nil
+}
+
+// GetLinkedDevice returns the device whose votes a device shares, which is
+// the device itself unless it has been linked to another
+func (s *DeviceService) GetLinkedDevice(deviceID string) (string, error) {
+	var devices []models.Device
+	err := s.db.Where("device_id = ?", deviceID).Limit(1).Find(&devices).Error
+	if err != nil || len(devices) == 0 || devices[0].LinkedTo == "" {
+		return deviceID, err
+	}
+	return devices[0].LinkedTo, nil
+}
+
+// LinkDevice has a device share the votes of another, taking along any
+// devices already linked to it so that links never chain
+func (s *DeviceService) LinkDevice(deviceID, toDeviceID string) error {
+	toDeviceID, err := s.GetLinkedDevice(toDeviceID)
+	if err != nil || toDeviceID == deviceID {
+		return err
+	}
+
+	now := time.Now()
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		device := models.Device{
+			DeviceID:  deviceID,
+			LinkedTo:  toDeviceID,
+			FirstSeen: now,
+			LastSeen:  now,
+		}
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "device_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"linked_to", "last_seen"}),
+		}).Create(&device).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.Device{}).Where("linked_to = ?", deviceID).Update("linked_to", toDeviceID).Error
+	})
+}
+
+// FindSimilarNames ranks the names used on any device against the given one,
+// returning the closest few that could be the same person
+func (s *DeviceService) FindSimilarNames(name string) ([]types.NameMatch, error) {
+	var names []string
+	err := s.db.Model(&models.DeviceName{}).Distinct("name").Pluck("name", &names).Error
+	if err != nil {
+		return nil, err
+	}
+
+	matches := rankNames(name, names)
+	if len(matches) > maxNameMatches {
+		matches = matches[:maxNameMatches]
+	}
+	return matches, nil
+}
+
+// GetDevicesByName groups the devices by the names used on them, most
+// recently seen first
+func (s *DeviceService) GetDevicesByName() (map[string][]models.Device, error) {
+	var devices []models.Device
+	err := s.db.Preload("Names").Order("last_seen DESC").Find(&devices).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string][]models.Device)
+	for _, device := range devices {
+		for _, name := range device.Names {
+			byName[name.Name] = append(byName[name.Name], device)
+		}
+	}
+	return byName, nil
+}
+
+// migrateDeviceNames moves names out of the old device_names table, which
+// was keyed on (device_id, name) alone, into the Device and DeviceName
+// models. It does nothing once the table has been upgraded. The whole
+// upgrade is one transaction, so a crash part way leaves the old table as it
+// was to be upgraded again on the next start.
+func migrateDeviceNames(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable("device_names") {
+		return nil
+	}
+	columns, err := migrator.ColumnTypes("device_names")
+	if err != nil {
+		return err
+	}
+	for _, column := range columns {
+		if column.Name() == "id" {
+			return nil
+		}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Migrator().RenameTable("device_names", "legacy_device_names"); err != nil {
+			return err
+		}
+		if err := tx.AutoMigrate(&models.Device{}, &models.DeviceName{}); err != nil {
+			return err
+		}
+
+		var rows []struct {
+			DeviceID string
+			Name     string
+		}
+		if err := tx.Table("legacy_device_names").Find(&rows).Error; err != nil {
+			return err
+		}
+
+		// The old table kept no times, so the upgrade stands in for them
+		now := time.Now()
+		devices := make(map[string]*models.Device)
+		var order []string
+		for _, row := range rows {
+			if devices[row.DeviceID] == nil {
+				devices[row.DeviceID] = &models.Device{DeviceID: row.DeviceID, FirstSeen: now, LastSeen: now}
+				order = append(order, row.DeviceID)
+			}
+			devices[row.DeviceID].LastName = row.Name
+			devices[row.DeviceID].Names = append(devices[row.DeviceID].Names, models.DeviceName{
+				Name:      row.Name,
+				FirstUsed: now,
+				LastUsed:  now,
+			})
+		}
+		for _, deviceID := range order {
+			if err := tx.Create(devices[deviceID]).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Migrator().DropTable("legacy_device_names")
+	})
+}
+
+// userAgentFamily boils a User-Agent header down to the browser and
+// platform, e.g. "Safari on iOS"
+func userAgentFamily(userAgent string) string {
+	if userAgent == "" {
+		return ""
+	}
+
+	// Order matters: most browsers also claim to be Chrome or Safari
+	browser := "Other"
+	for _, family := range []struct{ token, name string }{
+		{"Edg", "Edge"},
+		{"OPR", "Opera"},
+		{"SamsungBrowser", "Samsung Internet"},
+		{"Firefox", "Firefox"},
+		{"FxiOS", "Firefox"},
+		{"CriOS", "Chrome"},
+		{"Chrome", "Chrome"},
+		{"Safari", "Safari"},
+	} {
+		if strings.Contains(userAgent, family.token) {
+			browser = family.name
+			break
+		}
+	}
+
+	platform := ""
+	for _, family := range []struct{ token, name string }{
+		{"Android", "Android"},
+		{"iPhone", "iOS"},
+		{"iPad", "iOS"},
+		{"Windows", "Windows"},
+		{"Macintosh", "macOS"},
+		{"CrOS", "ChromeOS"},
+		{"Linux", "Linux"},
+	} {
+		if strings.Contains(userAgent, family.token) {
+			platform = family.name
+			break
+		}
+	}
+
+	if platform == "" {
+		return browser
+	}
+	return browser + " on " + platform
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/a-h/templ"
+	"github.com/thornzero/movie-poll/types"
+	"github.com/thornzero/movie-poll/views"
+)
+
+const displayTopMovies = 5
+
+// handleDisplay shows the full-screen results display for the tavern TV. It
+// needs no session, only the display token in ?token=.
+func (hr *HandlerRegistry) handleDisplay(w http.ResponseWriter, r *http.Request) {
+	if !checkDisplayToken(w, r) {
+		return
+	}
+	options, ok := resolveResultsOptions(w, r)
+	if !ok {
+		return
+	}
+
+	data, err := buildDisplayData(r, options)
+	if err != nil {
+		LogErrorf("Error fetching display results: %v", err)
+		http.Error(w, "Failed to load results", http.StatusInternalServerError)
+		return
+	}
+
+	views.DisplayPage(data).Render(r.Context(), w)
+}
+
+// handleDisplayStream pushes fresh stats and rankings to the display as
+// votes land
+func (hr *HandlerRegistry) handleDisplayStream(w http.ResponseWriter, r *http.Request) {
+	if !checkDisplayToken(w, r) {
+		return
+	}
+	options, ok := resolveResultsOptions(w, r)
+	if !ok {
+		return
+	}
+
+	serveEventStream(w, r, func(stream *http.ResponseController) error {
+		data, err := buildDisplayData(r, options)
+		if err != nil {
+			// Keep showing the last results
+			LogErrorf("Error fetching display results: %v", err)
+			return nil
+		}
+
+		fragments := []struct {
+			event     string
+			component templ.Component
+		}{
+			{views.LiveEventStats, views.StatsCards(data.Stats)},
+			{views.LiveEventResults, views.ResultsList(data.Movies, data.Selection)},
+		}
+		for _, fragment := range fragments {
+			var html bytes.Buffer
+			if err := fragment.component.Render(r.Context(), &html); err != nil {
+				return err
+			}
+			if err := writeLiveEvent(w, stream, fragment.event, html.String()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, LiveResults)
+}
+
+// checkDisplayToken compares ?token= with DISPLAY_TOKEN, writing an error
+// response and returning false if display mode is off or the token is wrong
+func checkDisplayToken(w http.ResponseWriter, r *http.Request) bool {
+	if Config.DisplayToken == "" {
+		http.NotFound(w, r)
+		return false
+	}
+	token := r.URL.Query().Get("token")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(Config.DisplayToken)) != 1 {
+		http.Error(w, "Invalid display token", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// buildDisplayData gathers the top movies and stats for the display
+func buildDisplayData(r *http.Request, options types.ResultsOptions) (views.DisplayData, error) {
+	data := views.DisplayData{
+		Token:        r.URL.Query().Get("token"),
+		JoinURL:      joinURL(r),
+		SlideSeconds: Config.DisplaySlideSeconds,
+		Selection:    resultsSelection(r),
+	}
+
+	results, err := DB.GetResultsList(options, types.ResultsQuery{
+		MaxSeenRatio:    1,
+		MaxPolarization: 1,
+		Page:            1,
+		PageSize:        displayTopMovies,
+	})
+	if err != nil {
+		return data, err
+	}
+	data.Movies = results.Results
+
+	if options.RoundID > 0 {
+		if round, err := DB.GetRound(options.RoundID); err == nil {
+			data.RoundName = round.Name
+		}
+	}
+
+	stats, err := DB.GetVotingStats(options.RoundID)
+	if err != nil {
+		LogErrorf("Error fetching voting stats: %v", err)
+		// Continue with empty stats
+		stats = &types.VotingStats{}
+	}
+	data.Stats = *stats
+	return data, nil
+}
+
+// joinURL is the address phones should open to vote, as seen by the browser
+// showing the display
+func joinURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + "/"
+}
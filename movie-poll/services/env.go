@@ -21,6 +21,20 @@ type EnvConfig struct {
 	LogDirectory  string
 	// voting constants
 	ParticipationThreshold int
+	VetoesPerUser          int
+	VetoPenalty            int // percent of appeal score removed per veto
+	// appeal scoring
+	AppealStrategy    string // default strategy until an admin picks one
+	AppealPriorMean   int    // Bayesian prior enthusiasm, in percent
+	AppealPriorWeight int    // Bayesian prior strength, in votes
+	// appeal history
+	AppealSnapshotHours    int // hours between scheduled snapshots, 0 to only take them by hand
+	AppealSnapshotKeepDays int // days snapshots are kept, 0 to keep them forever
+	// privacy
+	AnonymizeVoters bool // hide voter names in exported vote matrices
+	// TV display
+	DisplayToken        string // read-only token for /display, empty to turn it off
+	DisplaySlideSeconds int    // seconds each display panel stays on screen
 	// CORS configuration
 	CORSAllowedOrigins string
 }
@@ -43,6 +57,14 @@ func GetEnvInt(key, fallback string) int {
 	return value
 }
 
+func GetEnvBool(key, fallback string) bool {
+	value, err := strconv.ParseBool(Getenv(key, fallback))
+	if err != nil {
+		value, _ = strconv.ParseBool(fallback)
+	}
+	return value
+}
+
 func LoadEnvFile() {
 	// Try to load .env file, but don't fail if it doesn't exist
 	if err := godotenv.Load(); err != nil {
@@ -68,6 +90,16 @@ func NewEnvConfig() *EnvConfig {
 		LogFile:                Getenv("LOG_FILE", "server.log"),
 		LogDirectory:           Getenv("LOG_DIRECTORY", "logs"),
 		ParticipationThreshold: GetEnvInt("PARTICIPATION_THRESHOLD", "3"),
+		VetoesPerUser:          GetEnvInt("VETOES_PER_USER", "1"),
+		VetoPenalty:            GetEnvInt("VETO_PENALTY", "100"),
+		AppealStrategy:         Getenv("APPEAL_STRATEGY", "simple"),
+		AppealPriorMean:        GetEnvInt("APPEAL_PRIOR_MEAN", "50"),
+		AppealPriorWeight:      GetEnvInt("APPEAL_PRIOR_WEIGHT", "3"),
+		AppealSnapshotHours:    GetEnvInt("APPEAL_SNAPSHOT_HOURS", "24"),
+		AppealSnapshotKeepDays: GetEnvInt("APPEAL_SNAPSHOT_KEEP_DAYS", "30"),
+		AnonymizeVoters:        GetEnvBool("ANONYMIZE_VOTERS", "false"),
+		DisplayToken:           Getenv("DISPLAY_TOKEN", ""),
+		DisplaySlideSeconds:    GetEnvInt("DISPLAY_SLIDE_SECONDS", "15"),
 		CORSAllowedOrigins:     Getenv("CORS_ALLOWED_ORIGINS", "*"),
 	}
 }
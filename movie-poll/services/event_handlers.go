@@ -0,0 +1,300 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/thornzero/movie-poll/models"
+	"github.com/thornzero/movie-poll/views"
+)
+
+// handleEvents handles the upcoming movie nights page
+func (hr *HandlerRegistry) handleEvents(w http.ResponseWriter, r *http.Request) {
+	sessionData := Session.GetSessionData(r)
+	if sessionData.UserName == "" {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	// Keep tonight's event listed until the day is over
+	events, err := DB.GetEvents(time.Now().Add(-12 * time.Hour))
+	if err != nil {
+		LogErrorf("Error getting events: %v", err)
+		http.Error(w, "Failed to load events", http.StatusInternalServerError)
+		return
+	}
+
+	eventsData := views.EventsData{
+		UserName: sessionData.UserName,
+		Events:   convertEventsToInfo(events, sessionData),
+	}
+
+	views.EventsPage(eventsData).Render(r.Context(), w)
+}
+
+// handleEventRSVP handles a participant answering an event invitation
+func (hr *HandlerRegistry) handleEventRSVP(w http.ResponseWriter, r *http.Request) {
+	sessionData := Session.GetSessionData(r)
+	if sessionData.UserName == "" {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	eventID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || eventID <= 0 {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	err = DB.SetRSVP(uint(eventID), sessionData.UserName, sessionData.VoterDeviceID(), r.FormValue("status"))
+	switch {
+	case errors.Is(err, ErrInvalidRSVP):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, ErrEventFull):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		LogErrorf("Error saving RSVP for %s to event %d: %v", sessionData.UserName, eventID, err)
+		http.Error(w, "Failed to save RSVP", http.StatusInternalServerError)
+		return
+	}
+
+	if r.Header.Get("HX-Request") != "true" {
+		http.Redirect(w, r, "/events", http.StatusSeeOther)
+		return
+	}
+
+	event, err := DB.GetEvent(uint(eventID))
+	if err != nil {
+		LogErrorf("Error getting event %d: %v", eventID, err)
+		http.Error(w, "Failed to load event", http.StatusInternalServerError)
+		return
+	}
+	views.EventCard(convertEventToInfo(*event, sessionData)).Render(r.Context(), w)
+}
+
+// handleAdminEvents handles the events admin page
+func (hr *HandlerRegistry) handleAdminEvents(w http.ResponseWriter, r *http.Request) {
+	// Check if user is logged in as admin
+	sessionData := Session.GetSessionData(r)
+	if sessionData.AdminUser == nil {
+		http.Redirect(w, r, "/admin", http.StatusSeeOther)
+		return
+	}
+
+	events, err := DB.GetEvents(time.Time{})
+	if err != nil {
+		LogErrorf("Error getting events: %v", err)
+		http.Error(w, "Failed to load events", http.StatusInternalServerError)
+		return
+	}
+
+	movieInfos, err := getSlateMovieInfos(r)
+	if err != nil {
+		LogErrorf("Error getting movies for events: %v", err)
+		http.Error(w, "Failed to load movies", http.StatusInternalServerError)
+		return
+	}
+
+	eventsData := views.AdminEventsData{
+		AdminUser: views.AdminUserInfo{
+			ID:       sessionData.AdminUser.ID,
+			Username: sessionData.AdminUser.Username,
+		},
+		Events:          convertEventsToInfo(events, nil),
+		Movies:          movieInfos,
+		IncludeScreened: includeScreened(r),
+	}
+
+	views.AdminEventsPage(eventsData).Render(r.Context(), w)
+}
+
+// handleAdminCreateEvent handles scheduling a new movie night
+func (hr *HandlerRegistry) handleAdminCreateEvent(w http.ResponseWriter, r *http.Request) {
+	// Check if user is logged in as admin
+	sessionData := Session.GetSessionData(r)
+	if sessionData.AdminUser == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+
+	name := r.FormValue("name")
+	if name == "" {
+		http.Error(w, "Event name is required", http.StatusBadRequest)
+		return
+	}
+
+	startsAt, err := parseRoundTime(r.FormValue("starts_at"))
+	if err != nil || startsAt == nil {
+		http.Error(w, "Invalid start time", http.StatusBadRequest)
+		return
+	}
+
+	capacity := 0
+	if capacityStr := r.FormValue("capacity"); capacityStr != "" {
+		capacity, err = strconv.Atoi(capacityStr)
+		if err != nil || capacity < 0 {
+			http.Error(w, "Invalid capacity", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var movieIDs []uint
+	for _, idStr := range r.Form["movie_ids"] {
+		id, err := strconv.Atoi(idStr)
+		if err != nil || id <= 0 {
+			http.Error(w, "Invalid movie ID", http.StatusBadRequest)
+			return
+		}
+		movieIDs = append(movieIDs, uint(id))
+	}
+
+	_, err = DB.CreateEvent(name, *startsAt, r.FormValue("location"), capacity, movieIDs)
+	if errors.Is(err, ErrUnknownMovie) {
+		http.Error(w, "Unknown movie", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		LogErrorf("Error creating event %s: %v", name, err)
+		http.Error(w, "Failed to create event", http.StatusInternalServerError)
+		return
+	}
+
+	respondEventsChanged(w, r)
+}
+
+// handleAdminChooseEventMovie handles picking the movie an event will screen
+func (hr *HandlerRegistry) handleAdminChooseEventMovie(w http.ResponseWriter, r *http.Request) {
+	hr.updateEvent(w, r, func(eventID uint) error {
+		movieID, err := strconv.Atoi(r.FormValue("movie_id"))
+		if err != nil || movieID <= 0 {
+			return ErrMovieNotForEvent
+		}
+		return DB.ChooseEventMovie(eventID, uint(movieID))
+	})
+}
+
+// handleAdminDeleteEvent handles cancelling an event
+func (hr *HandlerRegistry) handleAdminDeleteEvent(w http.ResponseWriter, r *http.Request) {
+	hr.updateEvent(w, r, DB.DeleteEvent)
+}
+
+// updateEvent applies an admin action to the event in the URL
+func (hr *HandlerRegistry) updateEvent(w http.ResponseWriter, r *http.Request, action func(id uint) error) {
+	// Check if user is logged in as admin
+	sessionData := Session.GetSessionData(r)
+	if sessionData.AdminUser == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	eventID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || eventID <= 0 {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+
+	err = action(uint(eventID))
+	if errors.Is(err, ErrMovieNotForEvent) || errors.Is(err, ErrNoChosenMovie) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		LogErrorf("Error updating event %d: %v", eventID, err)
+		http.Error(w, "Failed to update event", http.StatusInternalServerError)
+		return
+	}
+
+	respondEventsChanged(w, r)
+}
+
+// respondEventsChanged re-renders the events list for HTMX requests and
+// redirects everything else back to the events page
+func respondEventsChanged(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("HX-Request") != "true" {
+		http.Redirect(w, r, "/admin/events", http.StatusSeeOther)
+		return
+	}
+
+	events, err := DB.GetEvents(time.Time{})
+	if err != nil {
+		LogErrorf("Error getting events: %v", err)
+		http.Error(w, "Failed to load events", http.StatusInternalServerError)
+		return
+	}
+
+	views.AdminEventsList(convertEventsToInfo(events, nil)).Render(r.Context(), w)
+}
+
+// convertEventsToInfo converts events to their display form. With session
+// data, each event also carries that participant's RSVP.
+func convertEventsToInfo(events []models.Event, sessionData *SessionData) []views.EventInfo {
+	infos := make([]views.EventInfo, len(events))
+	for i, event := range events {
+		infos[i] = convertEventToInfo(event, sessionData)
+	}
+	return infos
+}
+
+// convertEventToInfo converts a single event to its display form
+func convertEventToInfo(event models.Event, sessionData *SessionData) views.EventInfo {
+	candidates := make([]views.MovieInfo, len(event.Movies))
+	for i, movie := range event.Movies {
+		year := 0
+		if movie.Year != nil {
+			year = *movie.Year
+		}
+		candidates[i] = views.MovieInfo{
+			ID:    int(movie.ID),
+			Title: movie.Title,
+			Year:  year,
+		}
+	}
+
+	info := views.EventInfo{
+		ID:         int(event.ID),
+		Name:       event.Name,
+		StartsAt:   event.StartsAt,
+		Location:   event.Location,
+		Capacity:   event.Capacity,
+		Going:      event.GoingCount(),
+		Full:       event.IsFull(),
+		Screened:   len(event.Screenings) > 0,
+		Candidates: candidates,
+	}
+	if event.ChosenMovie != nil {
+		info.ChosenMovieID = int(event.ChosenMovie.ID)
+		info.ChosenTitle = event.ChosenMovie.Title
+	}
+	if sessionData != nil {
+		for _, rsvp := range event.RSVPs {
+			if rsvp.UserName == sessionData.UserName && rsvp.DeviceID == sessionData.VoterDeviceID() {
+				info.RSVP = rsvp.Status
+				break
+			}
+		}
+	}
+	return info
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/thornzero/movie-poll/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEventFull        = errors.New("event is full")
+	ErrInvalidRSVP      = errors.New("invalid RSVP status")
+	ErrMovieNotForEvent = errors.New("movie is not a candidate for this event")
+	ErrNoChosenMovie    = errors.New("event has no chosen movie")
+	ErrUnknownMovie     = errors.New("event candidate is not a known movie")
+)
+
+type EventService struct {
+	db *gorm.DB
+}
+
+func NewEventService(db *gorm.DB) *EventService {
+	return &EventService{db: db}
+}
+
+// CreateEvent schedules a movie night with the given candidate movies. It
+// returns ErrUnknownMovie if any of them doesn't exist.
+func (s *EventService) CreateEvent(name string, startsAt time.Time, location string, capacity int, movieIDs []uint) (*models.Event, error) {
+	event := models.Event{
+		Name:     name,
+		StartsAt: startsAt,
+		Location: location,
+		Capacity: capacity,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if len(movieIDs) > 0 {
+			if err := tx.Find(&event.Movies, movieIDs).Error; err != nil {
+				return err
+			}
+			distinct := make(map[uint]bool, len(movieIDs))
+			for _, id := range movieIDs {
+				distinct[id] = true
+			}
+			if len(event.Movies) != len(distinct) {
+				return ErrUnknownMovie
+			}
+		}
+		return tx.Create(&event).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// GetEvents returns events starting after the given time, soonest first.
+// A zero time returns every event.
+func (s *EventService) GetEvents(after time.Time) ([]models.Event, error) {
+	var events []models.Event
+	query := s.db.Preload("Movies").Preload("ChosenMovie").Preload("RSVPs").Preload("Screenings").Order("starts_at")
+	if !after.IsZero() {
+		query = query.Where("starts_at >= ?", after)
+	}
+	err := query.Find(&events).Error
+	return events, err
+}
+
+// GetEvent returns an event with its candidates and RSVPs
+func (s *EventService) GetEvent(id uint) (*models.Event, error) {
+	var event models.Event
+	err := s.db.Preload("Movies").Preload("ChosenMovie").Preload("RSVPs").Preload("Screenings").First(&event, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// ChooseMovie records which candidate the event will screen
+func (s *EventService) ChooseMovie(eventID, movieID uint) error {
+	event, err := s.GetEvent(eventID)
+	if err != nil {
+		return err
+	}
+	if len(event.Movies) > 0 && !event.HasMovie(movieID) {
+		return ErrMovieNotForEvent
+	}
+	return s.db.Model(&models.Event{ID: eventID}).Update("chosen_movie_id", movieID).Error
+}
+
+// DeleteEvent removes an event and its RSVPs. Screenings from the event are
+// kept so the movie stays retired.
+func (s *EventService) DeleteEvent(id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Screening{}).Where("event_id = ?", id).Update("event_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("event_id = ?", id).Delete(&models.EventRSVP{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Event{ID: id}).Association("Movies").Clear(); err != nil {
+			return err
+		}
+		return tx.Delete(&models.Event{}, id).Error
+	})
+}
+
+// SetRSVP records a participant's answer. Saying "going" to a full event
+// fails with ErrEventFull unless they were already going.
+func (s *EventService) SetRSVP(eventID uint, userName, deviceID, status string) error {
+	if status != models.RSVPGoing && status != models.RSVPMaybe && status != models.RSVPNotGoing {
+		return ErrInvalidRSVP
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var event models.Event
+		if err := tx.Preload("RSVPs").First(&event, eventID).Error; err != nil {
+			return err
+		}
+
+		var rsvp models.EventRSVP
+		err := tx.Where("event_id = ? AND user_name = ? AND device_id = ?", eventID, userName, deviceID).
+			First(&rsvp).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		alreadyGoing := rsvp.Status == models.RSVPGoing
+		if status == models.RSVPGoing && !alreadyGoing && event.IsFull() {
+			return ErrEventFull
+		}
+
+		rsvp.EventID = eventID
+		rsvp.UserName = userName
+		rsvp.DeviceID = deviceID
+		rsvp.Status = status
+		return tx.Save(&rsvp).Error
+	})
+}
+
+// GetRSVP returns a participant's RSVP status for an event, or "" if they
+// haven't answered
+func (s *EventService) GetRSVP(eventID uint, userName, deviceID string) (string, error) {
+	var rsvp models.EventRSVP
+	err := s.db.Where("event_id = ? AND user_name = ? AND device_id = ?", eventID, userName, deviceID).
+		First(&rsvp).Error
+	if err == gorm.ErrRecordNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return rsvp.Status, nil
+}
+
+// GetGoingAttendees returns the RSVPs of everyone going to an event
+func (s *EventService) GetGoingAttendees(eventID uint) ([]models.EventRSVP, error) {
+	var rsvps []models.EventRSVP
+	err := s.db.Where("event_id = ? AND status = ?", eventID, models.RSVPGoing).Find(&rsvps).Error
+	return rsvps, err
+}
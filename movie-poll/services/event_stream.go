@@ -0,0 +1,95 @@
+package services
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	liveHeartbeat    = 10 * time.Second // keeps proxies from closing a quiet stream
+	liveWriteTimeout = 10 * time.Second // drops a listener that stops reading
+)
+
+// serveEventStream holds a server-sent event stream open, calling send
+// whenever any of the brokers publishes, until the client goes away or the
+// server shuts down. send may write any number of events.
+func serveEventStream(w http.ResponseWriter, r *http.Request, send func(*http.ResponseController) error, brokers ...*UpdateBroker) {
+	// Merge the brokers into one mailbox, keeping the one-slot backpressure
+	updates := make(chan struct{}, 1)
+	closed := make(chan struct{})
+	var closeOnce sync.Once
+	for _, broker := range brokers {
+		ch, unsubscribe, err := broker.Subscribe()
+		if err != nil {
+			LogErrorf("Refusing live listener: %v", err)
+			http.Error(w, "Too many live listeners", http.StatusServiceUnavailable)
+			return
+		}
+		defer unsubscribe()
+
+		go func() {
+			for range ch {
+				select {
+				case updates <- struct{}{}:
+				default:
+				}
+			}
+			closeOnce.Do(func() { close(closed) })
+		}()
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	stream := http.NewResponseController(w)
+	if err := writeLiveEvent(w, stream, "", ""); err != nil {
+		LogErrorf("Error opening live stream: %v", err)
+		return
+	}
+
+	heartbeat := time.NewTicker(liveHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case <-r.Context().Done():
+			return
+		case <-closed:
+			return
+		case <-heartbeat.C:
+			err = writeLiveEvent(w, stream, "", "")
+		case <-updates:
+			err = send(stream)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// writeLiveEvent sends one server-sent event and flushes it. With no event
+// name it sends a comment, which keeps the connection alive.
+func writeLiveEvent(w http.ResponseWriter, stream *http.ResponseController, event, data string) error {
+	if err := stream.SetWriteDeadline(time.Now().Add(liveWriteTimeout)); err != nil {
+		return err
+	}
+
+	var message strings.Builder
+	if event == "" {
+		message.WriteString(": ping\n\n")
+	} else {
+		message.WriteString("event: " + event + "\n")
+		for _, line := range strings.Split(data, "\n") {
+			message.WriteString("data: " + line + "\n")
+		}
+		message.WriteString("\n")
+	}
+	if _, err := w.Write([]byte(message.String())); err != nil {
+		return err
+	}
+	return stream.Flush()
+}
@@ -1,6 +1,10 @@
 package services
 
 import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/thornzero/movie-poll/models"
@@ -8,12 +12,26 @@ import (
 	"gorm.io/gorm"
 )
 
+var ErrInvalidResultsSort = errors.New("invalid results sort")
+
 type GORMService struct {
-	db           *gorm.DB
-	movieService *MovieService
-	voteService  *VoteService
-	cacheService *CacheService
-	userService  *UserService
+	db                *gorm.DB
+	movieService      *MovieService
+	voteService       *VoteService
+	cacheService      *CacheService
+	userService       *UserService
+	roundService      *RoundService
+	ballotService     *BallotService
+	vetoService       *VetoService
+	revisionService   *RevisionService
+	eventService      *EventService
+	screeningService  *ScreeningService
+	nominationService *NominationService
+	tasteService      *TasteService
+	snapshotService   *SnapshotService
+	deviceService     *DeviceService
+	mergeService      *MergeService
+	pairingService    *PairingService
 }
 
 func NewGORMService() (*GORMService, error) {
@@ -23,30 +41,47 @@ func NewGORMService() (*GORMService, error) {
 		return nil, err
 	}
 
+	// Upgrade the old device_names table before the models take its name
+	if err := migrateDeviceNames(db); err != nil {
+		return nil, err
+	}
+
+	// Number existing vetoes before the slot index is built over them
+	if err := migrateVetoSlots(db); err != nil {
+		return nil, err
+	}
+
 	// Auto-migrate all models
-	err = db.AutoMigrate(&models.Movie{}, &models.Vote{}, &models.Appeal{}, &models.AdminUser{}, &models.User{})
+	err = db.AutoMigrate(&models.Movie{}, &models.Vote{}, &models.Appeal{}, &models.AdminUser{}, &models.User{}, &models.PollRound{}, &models.Ballot{}, &models.BallotEntry{}, &models.Veto{}, &models.VoteRevision{}, &models.Event{}, &models.EventRSVP{}, &models.Screening{}, &models.ScreeningAttendee{}, &models.Nomination{}, &models.Setting{}, &models.AppealSnapshot{}, &models.AppealSnapshotEntry{}, &models.Device{}, &models.DeviceName{}, &models.IdentityMerge{}, &models.IdentityMergeVote{}, &models.IdentityMergeVeto{}, &models.IdentityMergeBallot{}, &models.IdentityMergeRSVP{}, &models.IdentityMergeNomination{}, &models.IdentityMergeName{}, &models.IdentityMergeLink{}, &models.DevicePairing{})
 	if err != nil {
 		return nil, err
 	}
 
-	// Create device_names table for compatibility
-	err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS device_names (
-			device_id TEXT NOT NULL,
-			name TEXT NOT NULL,
-			PRIMARY KEY (device_id, name)
-		)
-	`).Error
-	if err != nil {
+	// Start the vote history from the existing votes
+	revisionService := NewRevisionService(db)
+	if err := revisionService.SeedFromVotes(); err != nil {
 		return nil, err
 	}
 
+	roundService := NewRoundService(db)
 	return &GORMService{
-		db:           db,
-		movieService: NewMovieService(db),
-		voteService:  NewVoteService(db),
-		cacheService: NewCacheService(db),
-		userService:  NewUserService(db),
+		db:                db,
+		movieService:      NewMovieService(db),
+		voteService:       NewVoteService(db),
+		cacheService:      NewCacheService(db),
+		userService:       NewUserService(db),
+		roundService:      roundService,
+		ballotService:     NewBallotService(db, roundService),
+		vetoService:       NewVetoService(db),
+		revisionService:   revisionService,
+		eventService:      NewEventService(db),
+		screeningService:  NewScreeningService(db),
+		nominationService: NewNominationService(db),
+		tasteService:      NewTasteService(db),
+		snapshotService:   NewSnapshotService(db),
+		deviceService:     NewDeviceService(db),
+		mergeService:      NewMergeService(db),
+		pairingService:    NewPairingService(db),
 	}, nil
 }
 
@@ -80,13 +115,25 @@ func (g *GORMService) SearchMovies(query string, limit int) ([]types.Movie, erro
 	return g.movieService.SearchMovies(query, limit)
 }
 
-func (g *GORMService) SubmitVote(vote types.Vote) (int64, error) {
-	err := g.voteService.SubmitVote(&vote)
-	return 0, err // Return 0 for compatibility, could be enhanced to return actual ID
+func (g *GORMService) SubmitVote(vote types.Vote, source string) (int64, error) {
+	err := g.voteService.SubmitVote(&vote, source)
+	if err == nil {
+		notifyResultsChanged()
+	}
+	return int64(vote.ID), err
 }
 
-func (g *GORMService) GetUserVotes(userName, deviceID string) ([]types.Vote, error) {
-	return g.voteService.GetUserVotes(userName, deviceID)
+// SubmitVotes saves votes in one transaction, filling in their round IDs
+func (g *GORMService) SubmitVotes(votes []types.Vote, source string) error {
+	err := g.voteService.SubmitVotes(votes, source)
+	if err == nil {
+		notifyResultsChanged()
+	}
+	return err
+}
+
+func (g *GORMService) GetUserVotes(userName, deviceID string, roundID uint) ([]types.Vote, error) {
+	return g.voteService.GetUserVotes(userName, deviceID, roundID)
 }
 
 func (g *GORMService) GetAllVotes() ([]types.Vote, error) {
@@ -115,23 +162,36 @@ func (g *GORMService) GetDB() *gorm.DB {
 	return g.db
 }
 
-// Additional methods needed for compatibility
+// Device methods
 func (g *GORMService) AddDeviceName(deviceID, name string) error {
-	// For now, we'll use a simple approach - store in a device_names table
-	// This could be enhanced later with a proper Device model
-	return g.db.Exec("INSERT OR IGNORE INTO device_names (device_id, name) VALUES (?, ?)", deviceID, name).Error
+	return g.deviceService.RecordName(deviceID, name)
 }
 
 func (g *GORMService) GetDeviceNames(deviceID string) ([]string, error) {
-	var names []string
-	err := g.db.Raw("SELECT name FROM device_names WHERE device_id = ?", deviceID).Scan(&names).Error
-	return names, err
+	return g.deviceService.GetNames(deviceID)
+}
+
+func (g *GORMService) FindSimilarNames(name string) ([]types.NameMatch, error) {
+	return g.deviceService.FindSimilarNames(name)
+}
+
+func (g *GORMService) GetDeviceMostRecentName(deviceID string) (string, error) {
+	return g.deviceService.GetLastName(deviceID)
+}
+
+// TouchDevice records that a device is still around and which browser it uses
+func (g *GORMService) TouchDevice(deviceID, userAgent string) error {
+	return g.deviceService.TouchDevice(deviceID, userAgent)
 }
 
-func (g *GORMService) FindSimilarNames(name string) ([]string, error) {
-	var names []string
-	err := g.db.Raw("SELECT DISTINCT name FROM device_names WHERE name LIKE ?", "%"+name+"%").Scan(&names).Error
-	return names, err
+// GetLinkedDevice returns the device whose votes a device shares
+func (g *GORMService) GetLinkedDevice(deviceID string) (string, error) {
+	return g.deviceService.GetLinkedDevice(deviceID)
+}
+
+// GetDevicesByName lists the devices each name has been used on
+func (g *GORMService) GetDevicesByName() (map[string][]models.Device, error) {
+	return g.deviceService.GetDevicesByName()
 }
 
 func (g *GORMService) AddMovieFromTMDB(tmdbID int) (string, error) {
@@ -142,12 +202,22 @@ func (g *GORMService) AddMovieFromTMDB(tmdbID int) (string, error) {
 		return movie.Title, nil // Movie exists, return existing title
 	}
 
-	// Get movie details from TMDB
-	tmdbData, err := TMDB.GetMovieDetails(tmdbID)
+	movieType, err := fetchTMDBMovie(tmdbID)
 	if err != nil {
 		return "", err
 	}
 
+	_, err = g.AddMovie(*movieType)
+	return movieType.Title, err
+}
+
+// fetchTMDBMovie looks a movie up on TMDB and converts it for adding
+func fetchTMDBMovie(tmdbID int) (*types.Movie, error) {
+	tmdbData, err := TMDB.GetMovieDetails(tmdbID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Convert TMDB Movie to types.Movie
 	overview := tmdbData.Overview
 	posterPath := tmdbData.PosterPath
@@ -160,7 +230,7 @@ func (g *GORMService) AddMovieFromTMDB(tmdbID int) (string, error) {
 	voteAverage := float64(tmdbData.VoteAverage)
 	voteCount := int(tmdbData.VoteCount)
 
-	movieType := &types.Movie{
+	return &types.Movie{
 		TMDBID:           &tmdbData.ID,
 		Title:            tmdbData.Title,
 		Overview:         &overview,
@@ -175,10 +245,7 @@ func (g *GORMService) AddMovieFromTMDB(tmdbID int) (string, error) {
 		VoteAverage:      &voteAverage,
 		VoteCount:        &voteCount,
 		Video:            tmdbData.Video,
-	}
-
-	_, err = g.AddMovie(*movieType)
-	return tmdbData.Title, err
+	}, nil
 }
 
 func (g *GORMService) FindDuplicateMovies() ([]models.DuplicateMovie, error) {
@@ -193,126 +260,458 @@ func (g *GORMService) FindDuplicateMovies() ([]models.DuplicateMovie, error) {
 	return duplicates, err
 }
 
+// RemoveDuplicateMovies folds every duplicate movie into the oldest copy of
+// it, votes and all, and returns how many were removed
+func (g *GORMService) RemoveDuplicateMovies() (int, error) {
+	duplicates, err := g.FindDuplicateMovies()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	defer func() {
+		if removed > 0 {
+			notifyResultsChanged()
+		}
+	}()
+	for _, dup := range duplicates {
+		var ids []int
+		for _, idStr := range strings.Split(dup.MovieIDs, ",") {
+			if id, err := strconv.Atoi(strings.TrimSpace(idStr)); err == nil {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) < 2 {
+			continue
+		}
+		sort.Ints(ids)
+		for _, id := range ids[1:] {
+			if err := g.movieService.MergeDuplicateMovie(uint(ids[0]), uint(id)); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+	}
+	return removed, nil
+}
+
 func (g *GORMService) ResetDatabase() error {
 	// Drop and recreate all tables
-	return g.db.Migrator().DropTable(&models.Movie{}, &models.Vote{}, &models.Appeal{}, &models.AdminUser{})
+	return g.db.Migrator().DropTable(&models.Movie{}, &models.Vote{}, &models.Appeal{}, &models.AdminUser{}, &models.PollRound{}, "poll_round_movies", &models.BallotEntry{}, &models.Ballot{}, &models.Veto{}, &models.VoteRevision{}, &models.EventRSVP{}, "event_movies", &models.Event{}, &models.ScreeningAttendee{}, &models.Screening{}, &models.Nomination{}, &models.Setting{}, &models.AppealSnapshotEntry{}, &models.AppealSnapshot{}, &models.IdentityMergeLink{}, &models.IdentityMergeName{}, &models.IdentityMergeNomination{}, &models.IdentityMergeRSVP{}, &models.IdentityMergeBallot{}, &models.IdentityMergeVeto{}, &models.IdentityMergeVote{}, &models.IdentityMerge{})
 }
 
 func (g *GORMService) DeleteAllVotes() error {
-	return g.db.Where("1 = 1").Delete(&models.Vote{}).Error
+	err := g.db.Transaction(func(tx *gorm.DB) error {
+		var votes []models.Vote
+		if err := tx.Find(&votes).Error; err != nil {
+			return err
+		}
+		if err := recordVoteDeletions(tx, votes, RevisionSourceDeleteAll); err != nil {
+			return err
+		}
+		if err := tx.Where("1 = 1").Delete(&models.Appeal{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("1 = 1").Delete(&models.Vote{}).Error
+	})
+	if err != nil {
+		return err
+	}
+	// The votes are gone even if clearing the vetoes fails
+	defer notifyResultsChanged()
+
+	// Clearing votes starts everyone over with a fresh veto allowance
+	return g.vetoService.DeleteAllVetoes()
 }
 
-func (g *GORMService) CalculateAppealScores() error {
-	// This is a complex operation that calculates appeal scores
-	// For now, we'll implement a simplified version
-	// TODO: Implement full appeal calculation logic
+// CalculateAppealScores rebuilds a round's appeal scores from scratch. Scores
+// are normally kept current as votes change, so this is only needed on demand.
+func (g *GORMService) CalculateAppealScores(roundID uint) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		return rebuildAppeals(tx, roundID)
+	})
+}
 
-	// Clear existing appeals
-	g.db.Where("1 = 1").Delete(&models.Appeal{})
+// RebuildAppealScores rebuilds the appeal scores of every round
+func (g *GORMService) RebuildAppealScores() error {
+	return g.db.Transaction(rebuildAllAppeals)
+}
 
-	// Get all movies with votes
-	var movies []models.Movie
-	err := g.db.Preload("Votes").Find(&movies).Error
-	if err != nil {
-		return err
-	}
+// buildAppeal scores a movie from its votes and the vetoes against it
+func buildAppeal(movieID uint, votes []models.Vote, vetoCount int, strategy AppealStrategy) models.Appeal {
+	totalVotes := len(votes)
+	uniqueVoters := make(map[string]bool)
+	seenCount := 0
 
-	// Calculate appeal scores for each movie
-	for _, movie := range movies {
-		if len(movie.Votes) == 0 {
-			continue
+	for _, vote := range votes {
+		uniqueVoters[vote.UserName] = true
+		if vote.Seen {
+			seenCount++
 		}
+	}
+
+	appealScore := strategy.Score(votes)
+	visibilityRatio := float64(seenCount) / float64(totalVotes)
+
+	// Vetoes knock the movie down (or out, at the default 100% penalty)
+	appealScore = applyVetoPenalty(appealScore, vetoCount)
+
+	return models.Appeal{
+		MovieID:         movieID,
+		Strategy:        strategy.Name(),
+		AppealScore:     appealScore,
+		VetoCount:       vetoCount,
+		Polarization:    polarization(votes),
+		TotalVotes:      totalVotes,
+		UniqueVoters:    len(uniqueVoters),
+		SeenCount:       seenCount,
+		VisibilityRatio: visibilityRatio,
+	}
+}
 
-		totalVotes := len(movie.Votes)
-		uniqueVoters := make(map[string]bool)
-		seenCount := 0
+// GetActiveAppealStrategy returns the strategy results are ranked by
+func (g *GORMService) GetActiveAppealStrategy() AppealStrategy {
+	return activeAppealStrategy(g.db)
+}
 
-		for _, vote := range movie.Votes {
-			uniqueVoters[vote.UserName] = true
-			if vote.Seen {
-				seenCount++
-			}
+// SetActiveAppealStrategy changes how appeal scores are calculated from now on
+func (g *GORMService) SetActiveAppealStrategy(name string) error {
+	if _, err := GetAppealStrategy(name); err != nil {
+		return err
+	}
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		if err := NewSettingService(tx).SetSetting(SettingAppealStrategy, name); err != nil {
+			return err
 		}
+		// The new strategy's scores went stale while it wasn't in use
+		return rebuildAllAppeals(tx)
+	})
+}
+
+// applyVetoPenalty removes VetoPenalty percent of an appeal score per veto
+func applyVetoPenalty(score float64, vetoes int) float64 {
+	penalty := 100
+	if Config != nil {
+		penalty = Config.VetoPenalty
+	}
+
+	factor := 1 - float64(vetoes*penalty)/100
+	if factor < 0 {
+		factor = 0
+	}
+	return score * factor
+}
 
-		// Simple appeal calculation
-		appealScore := float64(totalVotes) * float64(len(uniqueVoters)) / float64(seenCount+1)
-		visibilityRatio := float64(seenCount) / float64(totalVotes)
+// GetMovieTally counts the votes on one movie in a round, for the live vote
+// reveal
+func (g *GORMService) GetMovieTally(movieID, roundID uint) (*types.VotingSummary, error) {
+	movie, err := g.movieService.GetMovieByID(movieID)
+	if err != nil {
+		return nil, err
+	}
 
-		appeal := models.Appeal{
-			MovieID:         movie.ID,
-			AppealScore:     appealScore,
-			TotalVotes:      totalVotes,
-			UniqueVoters:    len(uniqueVoters),
-			SeenCount:       seenCount,
-			VisibilityRatio: visibilityRatio,
+	var votes []models.Vote
+	if err := g.db.Where("movie_id = ?", movieID).Scopes(scopeRound(roundID)).Find(&votes).Error; err != nil {
+		return nil, err
+	}
+
+	tally := &types.VotingSummary{
+		MovieID:   movie.ID,
+		Title:     movie.Title,
+		VoteCount: len(votes),
+	}
+	for _, vote := range votes {
+		if vote.Seen {
+			tally.SeenCount++
+		} else {
+			tally.NotSeenCount++
 		}
+	}
+	applyVibeDistribution(tally, votes)
+	return tally, nil
+}
 
-		g.db.Create(&appeal)
+// applyVibeDistribution fills in a results row's vibe histogram, mean and
+// median from the votes behind it
+func applyVibeDistribution(summary *types.VotingSummary, votes []models.Vote) {
+	var histogram types.VibeHistogram
+	vibes := make([]int, 0, len(votes))
+	for _, vote := range votes {
+		if vote.Vibe < 1 || vote.Vibe > len(histogram.Seen) {
+			continue
+		}
+		if vote.Seen {
+			histogram.Seen[vote.Vibe-1]++
+		} else {
+			histogram.NotSeen[vote.Vibe-1]++
+		}
+		vibes = append(vibes, vote.Vibe)
+	}
+	summary.Vibes = histogram
+	if len(vibes) == 0 {
+		return
 	}
 
-	return nil
+	total := 0
+	for _, vibe := range vibes {
+		total += vibe
+	}
+	summary.AverageVibe = float64(total) / float64(len(vibes))
+
+	sort.Ints(vibes)
+	middle := len(vibes) / 2
+	if len(vibes)%2 == 1 {
+		summary.MedianVibe = float64(vibes[middle])
+	} else {
+		summary.MedianVibe = float64(vibes[middle-1]+vibes[middle]) / 2
+	}
 }
 
-func (g *GORMService) GetResultsSummary() ([]types.VotingSummary, error) {
+// GetResultsSummary returns the ranked results for a round. With an EventID
+// the ranking is recomputed from the votes of people going to that event.
+func (g *GORMService) GetResultsSummary(options types.ResultsOptions) ([]types.VotingSummary, error) {
 	var summaries []types.VotingSummary
+	var err error
+	if options.EventID > 0 {
+		summaries, err = g.getAttendeeResultsSummary(options)
+	} else {
+		summaries, err = g.getRoundResultsSummary(options.RoundID)
+	}
+	if err != nil {
+		return nil, err
+	}
 
+	// Flag movies we've already watched together
+	screened, err := g.screeningService.GetScreeningDates()
+	if err != nil {
+		return nil, err
+	}
+	for i := range summaries {
+		if screenedAt, ok := screened[uint(summaries[i].MovieID)]; ok {
+			summaries[i].ScreenedAt = screenedAt.Unix()
+		}
+	}
+	return summaries, nil
+}
+
+// resultsSortFields maps each results sort key to the value it sorts by and
+// whether that value naturally sorts best-first ascending
+var resultsSortFields = map[string]struct {
+	value     func(types.VotingSummary) float64
+	ascending bool
+}{
+	types.ResultsSortAppeal:     {func(s types.VotingSummary) float64 { return s.AppealScore }, false},
+	types.ResultsSortVotes:      {func(s types.VotingSummary) float64 { return float64(s.VoteCount) }, false},
+	types.ResultsSortVibe:       {func(s types.VotingSummary) float64 { return s.AverageVibe }, true}, // vibe 1 is the best
+	types.ResultsSortVisibility: {func(s types.VotingSummary) float64 { return s.VisibilityRatio }, false},
+	types.ResultsSortDivisive:   {func(s types.VotingSummary) float64 { return s.Polarization }, false},
+}
+
+// GetResultsList returns one page of results, filtered and sorted as the
+// query asks
+func (g *GORMService) GetResultsList(options types.ResultsOptions, query types.ResultsQuery) (*types.ResultsList, error) {
+	if query.Sort == "" {
+		query.Sort = types.ResultsSortAppeal
+	}
+	field, ok := resultsSortFields[query.Sort]
+	if !ok {
+		return nil, ErrInvalidResultsSort
+	}
+	ascending := field.ascending
+	if query.Order != "" {
+		ascending = query.Order == "asc"
+	}
+
+	summaries, err := g.GetResultsSummary(options)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []types.VotingSummary{}
+	for _, summary := range summaries {
+		if query.Year > 0 && (summary.Year == nil || *summary.Year != query.Year) {
+			continue
+		}
+		if summary.VisibilityRatio < query.MinSeenRatio || summary.VisibilityRatio > query.MaxSeenRatio {
+			continue
+		}
+		if summary.VoteCount < query.MinParticipation {
+			continue
+		}
+		if summary.Polarization < query.MinPolarization || summary.Polarization > query.MaxPolarization {
+			continue
+		}
+		filtered = append(filtered, summary)
+	}
+
+	// Stable so ties keep their appeal order
+	sort.SliceStable(filtered, func(i, j int) bool {
+		if ascending {
+			return field.value(filtered[i]) < field.value(filtered[j])
+		}
+		return field.value(filtered[i]) > field.value(filtered[j])
+	})
+
+	list := &types.ResultsList{
+		Results:      filtered,
+		Page:         query.Page,
+		PageSize:     query.PageSize,
+		TotalResults: len(filtered),
+	}
+	if query.PageSize > 0 {
+		list.TotalPages = (len(filtered) + query.PageSize - 1) / query.PageSize
+		start := (query.Page - 1) * query.PageSize
+		if start > len(filtered) {
+			start = len(filtered)
+		}
+		end := start + query.PageSize
+		if end > len(filtered) {
+			end = len(filtered)
+		}
+		list.Results = filtered[start:end]
+	} else if len(filtered) > 0 {
+		list.TotalPages = 1
+	}
+	return list, nil
+}
+
+// getRoundResultsSummary reads the stored appeal scores for a round
+func (g *GORMService) getRoundResultsSummary(roundID uint) ([]types.VotingSummary, error) {
 	// Get movies with appeals
 	var appeals []models.Appeal
-	err := g.db.Preload("Movie").Find(&appeals).Error
+	err := g.db.Preload("Movie").Scopes(scopeRound(roundID)).
+		Where("strategy = ?", g.GetActiveAppealStrategy().Name()).
+		Order("appeal_score DESC").
+		Find(&appeals).Error
 	if err != nil {
 		return nil, err
 	}
 
+	// Load the votes behind each score for the vibe breakdown
+	var votes []models.Vote
+	if err := g.db.Scopes(scopeRound(roundID)).Find(&votes).Error; err != nil {
+		return nil, err
+	}
+	movieVotes := make(map[uint][]models.Vote)
+	for _, vote := range votes {
+		movieVotes[vote.MovieID] = append(movieVotes[vote.MovieID], vote)
+	}
+
+	var summaries []types.VotingSummary
 	for _, appeal := range appeals {
-		summary := types.VotingSummary{
-			MovieID:         int(appeal.Movie.ID),
-			Title:           appeal.Movie.Title,
-			Year:            appeal.Movie.Year,
-			Overview:        appeal.Movie.Overview,
-			PosterPath:      appeal.Movie.PosterPath,
-			ReleaseDate:     appeal.Movie.ReleaseDate,
-			VoteCount:       appeal.TotalVotes,
-			AverageVibe:     0, // TODO: Calculate from votes
-			AppealScore:     appeal.AppealScore,
-			SeenCount:       appeal.SeenCount,
-			NotSeenCount:    appeal.TotalVotes - appeal.SeenCount,
-			VisibilityRatio: appeal.VisibilityRatio,
-			TotalVotes:      appeal.TotalVotes,
-			UniqueVoters:    appeal.UniqueVoters,
-			CalculatedAt:    appeal.CalculatedAt.Unix(),
-		}
+		summary := convertAppealToSummary(appeal)
+		applyVibeDistribution(&summary, movieVotes[appeal.MovieID])
 		summaries = append(summaries, summary)
 	}
+	return summaries, nil
+}
+
+// getAttendeeResultsSummary ranks an event's movies using only the votes and
+// vetoes of participants who RSVP'd "going"
+func (g *GORMService) getAttendeeResultsSummary(options types.ResultsOptions) ([]types.VotingSummary, error) {
+	event, err := g.eventService.GetEvent(options.EventID)
+	if err != nil {
+		return nil, err
+	}
+
+	attendees, err := g.eventService.GetGoingAttendees(options.EventID)
+	if err != nil {
+		return nil, err
+	}
+	going := make(map[[2]string]bool, len(attendees))
+	for _, rsvp := range attendees {
+		going[[2]string{rsvp.UserName, rsvp.DeviceID}] = true
+	}
+
+	var votes []models.Vote
+	if err := g.db.Scopes(scopeRound(options.RoundID)).Find(&votes).Error; err != nil {
+		return nil, err
+	}
+	var vetoes []models.Veto
+	if err := g.db.Scopes(scopeRound(options.RoundID)).Find(&vetoes).Error; err != nil {
+		return nil, err
+	}
+
+	// Keep the attendees' votes on the event's candidates
+	counts := func(movieID uint, userName, deviceID string) bool {
+		if len(event.Movies) > 0 && !event.HasMovie(movieID) {
+			return false
+		}
+		return going[[2]string{userName, deviceID}]
+	}
+	movieVotes := make(map[uint][]models.Vote)
+	for _, vote := range votes {
+		if counts(vote.MovieID, vote.UserName, vote.DeviceID) {
+			movieVotes[vote.MovieID] = append(movieVotes[vote.MovieID], vote)
+		}
+	}
+	vetoCounts := make(map[uint]int)
+	for _, veto := range vetoes {
+		if counts(veto.MovieID, veto.UserName, veto.DeviceID) {
+			vetoCounts[veto.MovieID]++
+		}
+	}
+
+	movieIDs := make([]uint, 0, len(movieVotes))
+	for movieID := range movieVotes {
+		movieIDs = append(movieIDs, movieID)
+	}
+	var movies []models.Movie
+	if len(movieIDs) > 0 {
+		if err := g.db.Find(&movies, movieIDs).Error; err != nil {
+			return nil, err
+		}
+	}
 
+	strategy := g.GetActiveAppealStrategy()
+	now := time.Now()
+	var summaries []types.VotingSummary
+	for _, movie := range movies {
+		appeal := buildAppeal(movie.ID, movieVotes[movie.ID], vetoCounts[movie.ID], strategy)
+		appeal.Movie = movie
+		appeal.CalculatedAt = now
+		summary := convertAppealToSummary(appeal)
+		applyVibeDistribution(&summary, movieVotes[movie.ID])
+		summaries = append(summaries, summary)
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].AppealScore > summaries[j].AppealScore
+	})
 	return summaries, nil
 }
 
-func (g *GORMService) GetVotingStats() (*types.VotingStats, error) {
+func (g *GORMService) GetVotingStats(roundID uint) (*types.VotingStats, error) {
 	stats := &types.VotingStats{}
 
-	// Count total movies
+	// Count total movies (the round's slate when scoped to a round)
 	var movieCount int64
-	g.db.Model(&models.Movie{}).Count(&movieCount)
+	if roundID > 0 {
+		g.db.Table("poll_round_movies").Where("poll_round_id = ?", roundID).Count(&movieCount)
+	} else {
+		g.db.Model(&models.Movie{}).Count(&movieCount)
+	}
 	stats.TotalMovies = int(movieCount)
 
 	// Count total votes
 	var voteCount int64
-	g.db.Model(&models.Vote{}).Count(&voteCount)
+	g.db.Model(&models.Vote{}).Scopes(scopeRound(roundID)).Count(&voteCount)
 	stats.TotalVotes = int(voteCount)
 
 	// Count unique voters
 	var uniqueVoters int64
-	g.db.Model(&models.Vote{}).Distinct("user_name").Count(&uniqueVoters)
+	g.db.Model(&models.Vote{}).Scopes(scopeRound(roundID)).Distinct("user_name").Count(&uniqueVoters)
 	stats.UniqueVoters = int(uniqueVoters)
 
 	// Count movies with votes
 	var moviesWithVotes int64
-	g.db.Model(&models.Movie{}).Joins("JOIN votes ON movies.id = votes.movie_id").Distinct("movies.id").Count(&moviesWithVotes)
+	g.db.Model(&models.Vote{}).Scopes(scopeRound(roundID)).Distinct("movie_id").Count(&moviesWithVotes)
 	stats.MoviesWithVotes = int(moviesWithVotes)
 
 	// Calculate average appeal score
 	var avgAppeal float64
-	g.db.Model(&models.Appeal{}).Select("AVG(appeal_score)").Scan(&avgAppeal)
+	g.db.Model(&models.Appeal{}).Scopes(scopeRound(roundID)).
+		Where("strategy = ?", g.GetActiveAppealStrategy().Name()).
+		Select("AVG(appeal_score)").Scan(&avgAppeal)
 	stats.AverageAppealScore = avgAppeal
 
 	// Find most voted movie
@@ -322,6 +721,7 @@ func (g *GORMService) GetVotingStats() (*types.VotingStats, error) {
 	}
 	g.db.Model(&models.Vote{}).Select("movies.title, COUNT(*) as count").
 		Joins("JOIN movies ON votes.movie_id = movies.id").
+		Scopes(scopeRound(roundID)).
 		Group("movies.id, movies.title").
 		Order("count DESC").
 		Limit(1).
@@ -333,16 +733,225 @@ func (g *GORMService) GetVotingStats() (*types.VotingStats, error) {
 	return stats, nil
 }
 
-func (g *GORMService) GetDeviceMostRecentName(deviceID string) (string, error) {
-	var name string
-	err := g.db.Raw("SELECT name FROM device_names WHERE device_id = ? ORDER BY ROWID DESC LIMIT 1", deviceID).Scan(&name).Error
-	return name, err
+// Poll round methods
+func (g *GORMService) CreateRound(name string, movieIDs []uint, opensAt, closesAt *time.Time) (*models.PollRound, error) {
+	return g.roundService.CreateRound(name, movieIDs, opensAt, closesAt)
+}
+
+func (g *GORMService) GetRounds() ([]models.PollRound, error) {
+	return g.roundService.GetRounds()
+}
+
+func (g *GORMService) GetRound(id uint) (*models.PollRound, error) {
+	return g.roundService.GetRound(id)
+}
+
+func (g *GORMService) OpenRound(id uint) error {
+	return g.roundService.OpenRound(id)
+}
+
+func (g *GORMService) CloseRound(id uint) error {
+	return g.roundService.CloseRound(id)
+}
+
+func (g *GORMService) GetCurrentRound() (*models.PollRound, error) {
+	return g.roundService.GetCurrentRound()
+}
+
+func (g *GORMService) GetResultsRound() (*models.PollRound, error) {
+	return g.roundService.GetResultsRound()
+}
+
+// Ranked-choice ballot methods
+func (g *GORMService) SubmitBallot(userName, deviceID string, movieIDs []uint) error {
+	return g.ballotService.SubmitBallot(userName, deviceID, movieIDs)
+}
+
+func (g *GORMService) GetBallot(userName, deviceID string, roundID uint) ([]uint, error) {
+	return g.ballotService.GetBallot(userName, deviceID, roundID)
+}
+
+func (g *GORMService) TallyInstantRunoff(roundID uint) (*types.RunoffResult, error) {
+	return g.ballotService.TallyInstantRunoff(roundID)
+}
+
+// Veto methods
+func (g *GORMService) CastVeto(userName, deviceID string, movieID uint, allowance int) error {
+	err := g.vetoService.CastVeto(userName, deviceID, movieID, allowance)
+	if err == nil {
+		notifyResultsChanged()
+	}
+	return err
+}
+
+func (g *GORMService) WithdrawVeto(userName, deviceID string, movieID uint) error {
+	err := g.vetoService.WithdrawVeto(userName, deviceID, movieID)
+	if err == nil {
+		notifyResultsChanged()
+	}
+	return err
+}
+
+func (g *GORMService) GetUserVetoes(userName, deviceID string, roundID uint) ([]uint, error) {
+	return g.vetoService.GetUserVetoes(userName, deviceID, roundID)
+}
+
+// Vote history methods
+func (g *GORMService) GetMovieVoteTimeline(movieID uint) ([]models.VoteRevision, error) {
+	return g.revisionService.GetMovieTimeline(movieID)
+}
+
+func (g *GORMService) GetUserVoteTimeline(userName, deviceID string) ([]models.VoteRevision, error) {
+	return g.revisionService.GetUserTimeline(userName, deviceID)
+}
+
+func (g *GORMService) ReplayVoteRevisions() (int, error) {
+	return g.revisionService.ReplayRevisions()
+}
+
+// Event methods
+func (g *GORMService) CreateEvent(name string, startsAt time.Time, location string, capacity int, movieIDs []uint) (*models.Event, error) {
+	return g.eventService.CreateEvent(name, startsAt, location, capacity, movieIDs)
+}
+
+func (g *GORMService) GetEvents(after time.Time) ([]models.Event, error) {
+	return g.eventService.GetEvents(after)
+}
+
+func (g *GORMService) GetEvent(id uint) (*models.Event, error) {
+	return g.eventService.GetEvent(id)
+}
+
+func (g *GORMService) ChooseEventMovie(eventID, movieID uint) error {
+	return g.eventService.ChooseMovie(eventID, movieID)
+}
+
+func (g *GORMService) DeleteEvent(id uint) error {
+	return g.eventService.DeleteEvent(id)
+}
+
+func (g *GORMService) SetRSVP(eventID uint, userName, deviceID, status string) error {
+	return g.eventService.SetRSVP(eventID, userName, deviceID, status)
+}
+
+func (g *GORMService) GetRSVP(eventID uint, userName, deviceID string) (string, error) {
+	return g.eventService.GetRSVP(eventID, userName, deviceID)
+}
+
+// Screening methods
+func (g *GORMService) RecordScreening(movieID uint, screenedAt time.Time, eventID *uint, attendees []models.ScreeningAttendee, markSeen bool) (*models.Screening, error) {
+	screening, err := g.screeningService.RecordScreening(movieID, screenedAt, eventID, attendees, markSeen)
+	if err == nil {
+		notifyResultsChanged()
+	}
+	return screening, err
 }
 
-func (g *GORMService) UpdateDeviceLastSeen(deviceID string) error {
-	// This could be implemented with a last_seen column if needed
-	// For now, just return nil
-	return nil
+func (g *GORMService) GetScreeningDates() (map[uint]time.Time, error) {
+	return g.screeningService.GetScreeningDates()
+}
+
+func (g *GORMService) GetUnscreenedMovies(limit int) ([]types.Movie, error) {
+	return g.movieService.GetUnscreenedMovies(limit)
+}
+
+// Nomination methods
+func (g *GORMService) Nominate(nomination models.Nomination) (*models.Nomination, error) {
+	return g.nominationService.Nominate(nomination)
+}
+
+func (g *GORMService) GetNominations(status string) ([]models.Nomination, error) {
+	return g.nominationService.GetNominations(status)
+}
+
+func (g *GORMService) GetUserNominations(userName, deviceID string) ([]models.Nomination, error) {
+	return g.nominationService.GetUserNominations(userName, deviceID)
+}
+
+func (g *GORMService) GetMovieNominations() (map[uint]models.Nomination, error) {
+	return g.nominationService.GetMovieNominations()
+}
+
+// ApproveNomination adds a nominated movie from TMDB and closes the
+// nomination, returning the added movie's title
+func (g *GORMService) ApproveNomination(id uint, reviewer string) (string, error) {
+	nomination, err := g.nominationService.GetPendingNomination(id)
+	if err != nil {
+		return "", err
+	}
+
+	// Look the movie up before the transaction so it isn't held open over
+	// the call to TMDB
+	var details *types.Movie
+	_, err = g.movieService.GetMovieByTMDBID(nomination.TMDBID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		details, err = fetchTMDBMovie(nomination.TMDBID)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// Adding the movie and closing the nomination stand or fall together, so
+	// a nomination reviewed meanwhile leaves no movie behind
+	var title string
+	err = g.db.Transaction(func(tx *gorm.DB) error {
+		movies := NewMovieService(tx)
+		movie, err := movies.GetMovieByTMDBID(nomination.TMDBID)
+		if errors.Is(err, gorm.ErrRecordNotFound) && details != nil {
+			if err := movies.AddMovie(details); err != nil {
+				return err
+			}
+			movie, err = movies.GetMovieByTMDBID(nomination.TMDBID)
+		}
+		if err != nil {
+			return err
+		}
+
+		title = movie.Title
+		movieID := uint(movie.ID)
+		return NewNominationService(tx).Review(id, models.NominationApproved, &movieID, reviewer)
+	})
+	if err != nil {
+		return "", err
+	}
+	return title, nil
+}
+
+func (g *GORMService) RejectNomination(id uint, reviewer string) error {
+	return g.nominationService.Review(id, models.NominationRejected, nil, reviewer)
+}
+
+// GetPollMovies returns the movies people should be voting on: the slate of
+// the open round, or every movie not yet screened before rounds are used.
+// Once they are, it returns ErrNoOpenRound between rounds. A limit of 0
+// returns them all.
+func (g *GORMService) GetPollMovies(limit int) ([]types.Movie, error) {
+	round, err := g.roundService.GetCurrentRound()
+	if err != nil {
+		return nil, err
+	}
+	if round == nil {
+		inUse, err := g.roundService.RoundsInUse()
+		if err != nil {
+			return nil, err
+		}
+		if inUse {
+			return nil, ErrNoOpenRound
+		}
+		return g.movieService.GetUnscreenedMovies(limit)
+	}
+
+	var result []types.Movie
+	for _, movie := range round.Movies {
+		result = append(result, convertGORMMovieToType(movie))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Title < result[j].Title
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
 }
 
 func (g *GORMService) AuthenticateAdmin(username, password string) (*models.AdminUser, error) {
@@ -366,6 +975,159 @@ func (g *GORMService) AuthenticateAdmin(username, password string) (*models.Admi
 	return &admin, nil
 }
 
+// Appeal snapshot methods
+func (g *GORMService) TakeAppealSnapshot(reason string) (*models.AppealSnapshot, error) {
+	return g.snapshotService.TakeSnapshot(reason)
+}
+
+func (g *GORMService) GetLatestAppealSnapshotTime() (time.Time, error) {
+	return g.snapshotService.GetLatestSnapshotTime()
+}
+
+func (g *GORMService) PruneAppealSnapshots(before time.Time) (int64, error) {
+	return g.snapshotService.PruneSnapshots(before)
+}
+
+// GetAppealTrend follows a movie's rank and score through the kept
+// snapshots of a round under the active strategy
+func (g *GORMService) GetAppealTrend(movieID, roundID uint) (*types.AppealTrend, error) {
+	movie, err := g.movieService.GetMovieByID(movieID)
+	if err != nil {
+		return nil, err
+	}
+	strategy := g.GetActiveAppealStrategy()
+	points, err := g.snapshotService.GetMovieTrend(movieID, roundID, strategy.Name())
+	if err != nil {
+		return nil, err
+	}
+	return &types.AppealTrend{
+		MovieID:  movie.ID,
+		Title:    movie.Title,
+		Strategy: strategy.Label(),
+		Points:   points,
+	}, nil
+}
+
+// GetAppealMovers compares ranked results with the round's last snapshot and
+// returns up to limit movies whose rank changed the most. It returns nil
+// when there is no snapshot to compare with.
+func (g *GORMService) GetAppealMovers(roundID uint, results []types.VotingSummary, limit int) (*types.AppealMovers, error) {
+	snapshot, err := g.snapshotService.GetLatestRankings(roundID, g.GetActiveAppealStrategy().Name())
+	if err != nil || snapshot == nil {
+		return nil, err
+	}
+	previous := make(map[uint]models.AppealSnapshotEntry, len(snapshot.Entries))
+	for _, entry := range snapshot.Entries {
+		previous[entry.MovieID] = entry
+	}
+
+	movers := &types.AppealMovers{Since: snapshot.TakenAt.Unix(), Movers: []types.AppealMover{}}
+	for i, result := range results {
+		mover := types.AppealMover{
+			MovieID:     result.MovieID,
+			Title:       result.Title,
+			Rank:        i + 1,
+			ScoreChange: result.AppealScore,
+		}
+		if entry, ok := previous[uint(result.MovieID)]; ok {
+			mover.PreviousRank = entry.Rank
+			mover.ScoreChange -= entry.AppealScore
+		}
+		if mover.PreviousRank != mover.Rank {
+			movers.Movers = append(movers.Movers, mover)
+		}
+	}
+
+	// Biggest jumps first, newcomers after
+	distance := func(mover types.AppealMover) int {
+		if mover.Climb() < 0 {
+			return -mover.Climb()
+		}
+		return mover.Climb()
+	}
+	sort.SliceStable(movers.Movers, func(i, j int) bool {
+		a, b := movers.Movers[i], movers.Movers[j]
+		if (a.PreviousRank == 0) != (b.PreviousRank == 0) {
+			return b.PreviousRank == 0
+		}
+		return distance(a) > distance(b)
+	})
+	if len(movers.Movers) > limit {
+		movers.Movers = movers.Movers[:limit]
+	}
+	return movers, nil
+}
+
+// Identity merge methods
+func (g *GORMService) PreviewIdentityMerge(identities []types.Identity) (*types.MergePreview, error) {
+	return g.mergeService.PreviewMerge(identities)
+}
+
+func (g *GORMService) MergeIdentities(request types.MergeRequest) (*models.IdentityMerge, error) {
+	merge, err := g.mergeService.MergeIdentities(request)
+	if err == nil {
+		notifyResultsChanged()
+	}
+	return merge, err
+}
+
+func (g *GORMService) UndoIdentityMerge(mergeID uint) (*models.IdentityMerge, error) {
+	merge, err := g.mergeService.UndoMerge(mergeID)
+	if err == nil {
+		notifyResultsChanged()
+	}
+	return merge, err
+}
+
+func (g *GORMService) GetIdentityMerges(limit int) ([]models.IdentityMerge, error) {
+	return g.mergeService.GetMerges(limit)
+}
+
+// GetMergeGeneration returns a value that changes whenever identities are
+// merged or a merge is undone
+func (g *GORMService) GetMergeGeneration() (string, error) {
+	return NewSettingService(g.db).GetSetting(SettingMergeGeneration, "")
+}
+
+// Device pairing methods
+func (g *GORMService) CreatePairingCode(owner types.Identity) (*models.DevicePairing, error) {
+	return g.pairingService.CreateCode(owner)
+}
+
+func (g *GORMService) ClaimPairingCode(code, deviceID string, claimant types.Identity) (*models.DevicePairing, error) {
+	pairing, err := g.pairingService.ClaimCode(code, deviceID, claimant)
+	if err == nil && claimant.UserName != "" {
+		notifyResultsChanged()
+	}
+	return pairing, err
+}
+
+// Taste similarity methods
+
+// tasteMinShared is how many movies two voters must both have rated before
+// their taste is compared
+func tasteMinShared() int {
+	if Config != nil && Config.ParticipationThreshold > 0 {
+		return Config.ParticipationThreshold
+	}
+	return 3
+}
+
+func (g *GORMService) GetTasteMatrix() (*types.TasteMatrix, error) {
+	return g.tasteService.GetTasteMatrix(tasteMinShared())
+}
+
+func (g *GORMService) GetTasteTwin(userName, deviceID string) (*types.TasteTwin, error) {
+	return g.tasteService.GetTasteTwin(userName, deviceID, tasteMinShared())
+}
+
+// tasteNeighbours is how many similar voters a predicted vibe draws on
+const tasteNeighbours = 5
+
+func (g *GORMService) PredictVibes(userName, deviceID string) (map[uint]types.VibePrediction, error) {
+	return g.tasteService.PredictVibes(userName, deviceID, tasteMinShared(), tasteNeighbours)
+}
+
 // User management methods
 func (g *GORMService) GetUsers(limit int) ([]models.User, error) {
 	return g.userService.GetUsers(limit)
@@ -2,6 +2,7 @@ package services
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -50,6 +51,58 @@ func (hr *HandlerRegistry) registerHandlers() {
 	hr.handlers["admin-list-votes"] = hr.handleAdminListVotes
 	hr.handlers["admin-delete-all-votes"] = hr.handleAdminDeleteAllVotes
 	hr.handlers["admin-delete-movie"] = hr.handleAdminDeleteMovie
+	hr.handlers["admin-vote-history"] = hr.handleAdminVoteHistory
+
+	// Poll round handlers
+	hr.handlers["admin-rounds"] = hr.handleAdminRounds
+	hr.handlers["admin-create-round"] = hr.handleAdminCreateRound
+	hr.handlers["admin-open-round"] = hr.handleAdminOpenRound
+	hr.handlers["admin-close-round"] = hr.handleAdminCloseRound
+
+	// Event handlers
+	hr.handlers["events"] = hr.handleEvents
+	hr.handlers["event-rsvp"] = hr.handleEventRSVP
+	hr.handlers["admin-events"] = hr.handleAdminEvents
+	hr.handlers["admin-create-event"] = hr.handleAdminCreateEvent
+	hr.handlers["admin-choose-event-movie"] = hr.handleAdminChooseEventMovie
+	hr.handlers["admin-delete-event"] = hr.handleAdminDeleteEvent
+
+	// Nomination handlers
+	hr.handlers["nominate"] = hr.handleNominate
+	hr.handlers["nomination-search"] = hr.handleNominationSearch
+	hr.handlers["submit-nomination"] = hr.handleSubmitNomination
+	hr.handlers["admin-approve-nomination"] = hr.handleAdminApproveNomination
+	hr.handlers["admin-reject-nomination"] = hr.handleAdminRejectNomination
+
+	// Screening handlers
+	hr.handlers["admin-mark-movie-screened"] = hr.handleAdminMarkMovieScreened
+	hr.handlers["admin-mark-event-screened"] = hr.handleAdminMarkEventScreened
+
+	// Appeal strategy handlers
+	hr.handlers["admin-set-appeal-strategy"] = hr.handleAdminSetAppealStrategy
+	hr.handlers["admin-rebuild-appeals"] = hr.handleAdminRebuildAppeals
+	hr.handlers["admin-take-appeal-snapshot"] = hr.handleAdminTakeAppealSnapshot
+	hr.handlers["appeal-trend"] = hr.handleAppealTrend
+
+	// Taste similarity handlers
+	hr.handlers["admin-taste"] = hr.handleAdminTaste
+	hr.handlers["admin-taste-api"] = hr.handleAdminTasteAPI
+
+	// Live vote handlers
+	hr.handlers["live"] = hr.handleLive
+	hr.handlers["live-stream"] = hr.handleLiveStream
+	hr.handlers["admin-live"] = hr.handleAdminLive
+	hr.handlers["admin-live-stream"] = hr.handleAdminLiveStream
+	hr.handlers["admin-live-action"] = hr.handleAdminLiveAction
+
+	// Device pairing handlers
+	hr.handlers["link-device"] = hr.handleLinkDevice
+	hr.handlers["create-pairing-code"] = hr.handleCreatePairingCode
+	hr.handlers["claim-pairing-code"] = hr.handleClaimPairingCode
+
+	// TV display handlers
+	hr.handlers["display"] = hr.handleDisplay
+	hr.handlers["display-stream"] = hr.handleDisplayStream
 
 	// User management handlers
 	hr.handlers["admin-users"] = hr.handleAdminUsers
@@ -58,6 +111,12 @@ func (hr *HandlerRegistry) registerHandlers() {
 	hr.handlers["admin-user-update-stats"] = hr.handleAdminUserUpdateStats
 	hr.handlers["admin-users-api"] = hr.handleAdminUsersAPI
 
+	// Identity merge handlers
+	hr.handlers["admin-merge-preview"] = hr.handleAdminMergePreview
+	hr.handlers["admin-merge-identities"] = hr.handleAdminMergeIdentities
+	hr.handlers["admin-merges"] = hr.handleAdminMerges
+	hr.handlers["admin-undo-merge"] = hr.handleAdminUndoMerge
+
 	// Debug handlers
 	hr.handlers["debug"] = hr.handleDebug
 	hr.handlers["debug-session"] = hr.handleDebugSession
@@ -81,10 +140,18 @@ func (hr *HandlerRegistry) registerHandlers() {
 	hr.handlers["voting-interest"] = hr.handleVotingInterest
 	hr.handlers["voting-next-movie"] = hr.handleVotingNextMovie
 	hr.handlers["voting-change-vote"] = hr.handleVotingChangeVote
+	hr.handlers["voting-veto"] = hr.handleVotingVeto
+	hr.handlers["voting-withdraw-veto"] = hr.handleVotingWithdrawVeto
+
+	// Ranked-choice handlers
+	hr.handlers["ranked-ballot"] = hr.handleRankedBallot
+	hr.handlers["submit-ranked-ballot"] = hr.handleSubmitRankedBallot
 
 	// Results API handlers
 	hr.handlers["results-summary"] = hr.handleResultsSummary
 	hr.handlers["results-list"] = hr.handleResultsList
+	hr.handlers["results-live"] = hr.handleResultsLive
+	hr.handlers["admin-export-results"] = hr.handleAdminExportResults
 	hr.handlers["logout"] = hr.handleLogout
 
 	// Movie management handlers
@@ -117,23 +184,28 @@ func (hr *HandlerRegistry) handleFavicon(w http.ResponseWriter, r *http.Request)
 // API handlers
 
 func (hr *HandlerRegistry) handleMovies(w http.ResponseWriter, r *http.Request) {
-	// Get all movies from database
-	movies, err := DB.GetMovies(Config.MovieLimit)
+	// Get session data to check for existing votes
+	sessionData := Session.GetSessionData(r)
+	Session.SyncSessionRound(r, sessionData)
+
+	// Get the movies on the current slate in this user's voting order
+	movies, err := getVotingSequence(sessionData)
+	if errors.Is(err, ErrNoOpenRound) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
 	if err != nil {
 		LogErrorf("Error fetching movies: %v", err)
 		http.Error(w, "Failed to fetch movies", http.StatusInternalServerError)
 		return
 	}
 
-	// Get session data to check for existing votes
-	sessionData := Session.GetSessionData(r)
-
 	// Create response with movies and vote status
 	response := map[string]interface{}{
 		"movies":       movies,
 		"user_votes":   sessionData.Votes,
 		"total_movies": len(movies),
-		"voted_movies": len(sessionData.Votes),
+		"voted_movies": countVoted(movies, sessionData.Votes),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -178,16 +250,15 @@ func (hr *HandlerRegistry) handleVote(w http.ResponseWriter, r *http.Request) {
 		UserName:  sessionData.UserName,
 		Vibe:      voteRequest.Vibe,
 		Seen:      voteRequest.Seen,
-		DeviceID:  sessionData.DeviceID,
+		DeviceID:  sessionData.VoterDeviceID(),
 		CreatedAt: time.Now().Unix(),
 		UpdatedAt: time.Now().Unix(),
 	}
 
 	// Submit vote to database
-	_, err := DB.SubmitVote(vote)
+	_, err := DB.SubmitVote(vote, r.URL.Path)
 	if err != nil {
-		LogErrorf("Error submitting vote: %v", err)
-		http.Error(w, "Failed to submit vote", http.StatusInternalServerError)
+		writeVoteError(w, err)
 		return
 	}
 
@@ -205,10 +276,132 @@ func (hr *HandlerRegistry) handleVote(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// writeVoteError reports a failed vote submission, distinguishing votes the
+// current poll round refuses from server errors
+func writeVoteError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrNoOpenRound) || errors.Is(err, ErrRoundClosed) || errors.Is(err, ErrMovieNotInRound) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	LogErrorf("Error submitting vote: %v", err)
+	http.Error(w, "Failed to submit vote", http.StatusInternalServerError)
+}
+
+// handleBatchVote handles a queue of votes flushed by a client in one
+// request. The votes are saved all-or-nothing and each gets its own result.
 func (hr *HandlerRegistry) handleBatchVote(w http.ResponseWriter, r *http.Request) {
+	// Parse JSON request body
+	var voteRequests []struct {
+		MovieID int  `json:"movie_id"`
+		Vibe    int  `json:"vibe"`
+		Seen    bool `json:"seen"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&voteRequests); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if len(voteRequests) == 0 {
+		http.Error(w, "No votes submitted", http.StatusBadRequest)
+		return
+	}
+
+	// Get session data
+	sessionData := Session.GetSessionData(r)
+	if sessionData.UserName == "" {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	// Validate every vote against the same rules as handleVote
+	now := time.Now().Unix()
+	votes := make([]types.Vote, len(voteRequests))
+	results := make([]types.BatchVoteResult, len(voteRequests))
+	valid := true
+	for i, voteRequest := range voteRequests {
+		results[i] = types.BatchVoteResult{Index: i, MovieID: voteRequest.MovieID}
+
+		if voteRequest.MovieID <= 0 {
+			results[i].Error = "Invalid movie ID"
+			valid = false
+			continue
+		}
+		if voteRequest.Vibe < 1 || voteRequest.Vibe > 6 {
+			results[i].Error = "Invalid vibe rating (must be 1-6)"
+			valid = false
+			continue
+		}
+
+		votes[i] = types.Vote{
+			MovieID:   voteRequest.MovieID,
+			UserName:  sessionData.UserName,
+			Vibe:      voteRequest.Vibe,
+			Seen:      voteRequest.Seen,
+			DeviceID:  sessionData.VoterDeviceID(),
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+	}
+
+	if !valid {
+		writeBatchVoteResults(w, http.StatusBadRequest, results, "Batch rejected: some votes are invalid")
+		return
+	}
+
+	// Submit all votes in one transaction
+	err := DB.SubmitVotes(votes, r.URL.Path)
+	if err != nil {
+		status := http.StatusInternalServerError
+		message := "Failed to submit votes"
+
+		var batchErr *BatchVoteError
+		if errors.As(err, &batchErr) {
+			results[batchErr.Index].Error = message
+			if errors.Is(err, ErrNoOpenRound) || errors.Is(err, ErrRoundClosed) || errors.Is(err, ErrMovieNotInRound) {
+				status = http.StatusConflict
+				results[batchErr.Index].Error = batchErr.Err.Error()
+			}
+		}
+		if status == http.StatusInternalServerError {
+			LogErrorf("Error submitting batch of %d votes: %v", len(votes), err)
+		}
+		writeBatchVoteResults(w, status, results, "Batch rolled back: no votes were saved")
+		return
+	}
+
+	// Update session data and user stats once for the whole batch
+	for i := range votes {
+		sessionData.Votes[votes[i].MovieID] = votes[i]
+		results[i].Success = true
+		results[i].Vote = &votes[i]
+	}
+	Session.PutSessionData(r, sessionData)
+
+	if err := DB.UpdateUserStats(sessionData.UserName, sessionData.VoterDeviceID()); err != nil {
+		LogErrorf("Error updating stats for %s: %v", sessionData.UserName, err)
+	}
+
+	writeBatchVoteResults(w, http.StatusOK, results, "Votes submitted successfully")
+}
+
+// writeBatchVoteResults writes the per-vote outcome of a batch submission
+func writeBatchVoteResults(w http.ResponseWriter, status int, results []types.BatchVoteResult, message string) {
+	saved := 0
+	for _, result := range results {
+		if result.Success {
+			saved++
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"success": true}`))
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": status == http.StatusOK,
+		"message": message,
+		"saved":   saved,
+		"results": results,
+	})
 }
 
 func (hr *HandlerRegistry) handleStartPoll(w http.ResponseWriter, r *http.Request) {
@@ -241,6 +434,7 @@ func (hr *HandlerRegistry) handleStartPoll(w http.ResponseWriter, r *http.Reques
 
 	sessionData.UserName = username
 	Session.PutSessionData(r, sessionData)
+	Session.SyncSessionRound(r, sessionData)
 
 	// Debug: Check if session is being created
 	sessionIDCheck := Session.Token(r.Context())
@@ -249,8 +443,12 @@ func (hr *HandlerRegistry) handleStartPoll(w http.ResponseWriter, r *http.Reques
 	// Debug: Check response headers before rendering
 	LogDebugf("Response headers before render: %v", w.Header())
 
-	// Get movies from database
-	movies, err := DB.GetMovies(Config.MovieLimit)
+	// Get movies in this user's voting order
+	movies, err := getVotingSequence(sessionData)
+	if errors.Is(err, ErrNoOpenRound) {
+		views.NoOpenRoundPage(sessionData.UserName).Render(r.Context(), w)
+		return
+	}
 	if err != nil {
 		LogErrorf("Error fetching movies: %v", err)
 		http.Error(w, "Failed to load movies", http.StatusInternalServerError)
@@ -258,7 +456,7 @@ func (hr *HandlerRegistry) handleStartPoll(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Render the movie poll page
-	renderMoviePollPage(w, r, movies, sessionData)
+	renderMoviePollPage(w, r, movies, sessionData, nextUnvotedIndex(movies, sessionData.Votes, -1))
 
 	// Debug: Check response headers after rendering
 	LogDebugf("Response headers after render: %v", w.Header())
@@ -297,39 +495,32 @@ func (hr *HandlerRegistry) handleCheckNameSimilarity(w http.ResponseWriter, r *h
 		return
 	}
 
-	// If device has names, check if any match closely
-	if len(deviceNames) > 0 {
-		for _, existingName := range deviceNames {
-			similarity := calculateNameSimilarity(name, existingName)
-			if similarity >= 0.8 {
-				// Very similar to existing name, show all device names for selection
-				w.WriteHeader(http.StatusOK)
-				response := fmt.Sprintf(`{"hasExisting": true, "deviceNames": %s, "closestMatch": "%s", "similarity": %.2f}`, formatStringArray(deviceNames), existingName, similarity)
-				w.Write([]byte(response))
-				return
-			}
-		}
+	// If one of the device's names matches closely, offer all of them for
+	// selection
+	if matches := rankNames(name, deviceNames); len(matches) > 0 {
+		json.NewEncoder(w).Encode(types.NameCheck{
+			HasExisting:  true,
+			DeviceNames:  deviceNames,
+			ClosestMatch: matches[0].Name,
+			Similarity:   matches[0].Score,
+			Matches:      matches,
+		})
+		return
 	}
 
 	// Look for similar names in the database
-	similarNames, err := DB.FindSimilarNames(name)
+	matches, err := DB.FindSimilarNames(name)
 	if err != nil {
 		LogErrorf("Error finding similar names: %v", err)
 		http.Error(w, "Database error", http.StatusInternalServerError)
 		return
 	}
 
-	if len(similarNames) > 0 {
-		// Found similar names, return them for verification
-		w.WriteHeader(http.StatusOK)
-		response := fmt.Sprintf(`{"hasSimilar": true, "similarNames": %s}`, formatStringArray(similarNames))
-		w.Write([]byte(response))
-		return
+	check := types.NameCheck{HasSimilar: len(matches) > 0, Matches: matches}
+	for _, match := range matches {
+		check.SimilarNames = append(check.SimilarNames, match.Name)
 	}
-
-	// No similar names found
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"hasSimilar": false}`))
+	json.NewEncoder(w).Encode(check)
 }
 
 // handleConfirmName confirms a name choice (either new or existing)
@@ -375,23 +566,6 @@ func (hr *HandlerRegistry) handleConfirmName(w http.ResponseWriter, r *http.Requ
 	}
 }
 
-// formatStringArray formats a string array as JSON
-func formatStringArray(arr []string) string {
-	if len(arr) == 0 {
-		return "[]"
-	}
-
-	result := "["
-	for i, s := range arr {
-		if i > 0 {
-			result += ","
-		}
-		result += fmt.Sprintf(`"%s"`, s)
-	}
-	result += "]"
-	return result
-}
-
 func (hr *HandlerRegistry) handleSearch(w http.ResponseWriter, r *http.Request) {
 	var movieID MovieID
 	var err error
@@ -454,18 +628,6 @@ func (hr *HandlerRegistry) handleUpdateAppeal(w http.ResponseWriter, r *http.Req
 	w.Write([]byte(`{"success": true}`))
 }
 
-func (hr *HandlerRegistry) handleResultsSummary(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"summary": {}}`))
-}
-
-func (hr *HandlerRegistry) handleResultsList(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"results": []}`))
-}
-
 func (hr *HandlerRegistry) handleLogout(w http.ResponseWriter, r *http.Request) {
 	// Clear session data
 	sessionData := Session.GetSessionData(r)
@@ -480,6 +642,13 @@ func (hr *HandlerRegistry) handleLogout(w http.ResponseWriter, r *http.Request)
 }
 
 func (hr *HandlerRegistry) handleAddMovie(w http.ResponseWriter, r *http.Request) {
+	// Only admins add movies directly; everyone else goes through nominations
+	sessionData := Session.GetSessionData(r)
+	if sessionData.AdminUser == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
 	// Parse JSON request body
 	var addRequest struct {
 		TMDBID int `json:"tmdb_id"`
@@ -663,15 +832,14 @@ func (hr *HandlerRegistry) handleVotingRating(w http.ResponseWriter, r *http.Req
 		UserName:  sessionData.UserName,
 		Vibe:      vibe,
 		Seen:      true,
-		DeviceID:  sessionData.DeviceID,
+		DeviceID:  sessionData.VoterDeviceID(),
 		CreatedAt: time.Now().Unix(),
 		UpdatedAt: time.Now().Unix(),
 	}
 
-	_, err = DB.SubmitVote(vote)
+	_, err = DB.SubmitVote(vote, r.URL.Path)
 	if err != nil {
-		LogErrorf("Error submitting vote: %v", err)
-		http.Error(w, "Failed to submit vote", http.StatusInternalServerError)
+		writeVoteError(w, err)
 		return
 	}
 
@@ -718,15 +886,14 @@ func (hr *HandlerRegistry) handleVotingInterest(w http.ResponseWriter, r *http.R
 		UserName:  sessionData.UserName,
 		Vibe:      vibe,
 		Seen:      false,
-		DeviceID:  sessionData.DeviceID,
+		DeviceID:  sessionData.VoterDeviceID(),
 		CreatedAt: time.Now().Unix(),
 		UpdatedAt: time.Now().Unix(),
 	}
 
-	_, err = DB.SubmitVote(vote)
+	_, err = DB.SubmitVote(vote, r.URL.Path)
 	if err != nil {
-		LogErrorf("Error submitting vote: %v", err)
-		http.Error(w, "Failed to submit vote", http.StatusInternalServerError)
+		writeVoteError(w, err)
 		return
 	}
 
@@ -759,61 +926,38 @@ func (hr *HandlerRegistry) handleVotingNextMovie(w http.ResponseWriter, r *http.
 		return
 	}
 
-	// Get all movies
-	movies, err := DB.GetMovies(Config.MovieLimit)
+	// Get movies in this user's voting order
+	movies, err := getVotingSequence(sessionData)
+	if errors.Is(err, ErrNoOpenRound) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
 	if err != nil {
 		LogErrorf("Error fetching movies: %v", err)
 		http.Error(w, "Failed to load movies", http.StatusInternalServerError)
 		return
 	}
 
-	// Find next movie that hasn't been voted on
-	var nextMovie *types.Movie
-	for _, movie := range movies {
-		if movie.ID > currentMovieID {
-			// Check if user has voted on this movie
-			if _, hasVoted := sessionData.Votes[movie.ID]; !hasVoted {
-				nextMovie = &movie
-				break
-			}
-		}
-	}
-
-	// If no next movie found, check if all movies are voted on
-	if nextMovie == nil {
-		allVoted := true
-		for _, movie := range movies {
-			if _, hasVoted := sessionData.Votes[movie.ID]; !hasVoted {
-				allVoted = false
-				break
-			}
-		}
-
-		if allVoted {
-			// All movies voted on, redirect to results
-			http.Redirect(w, r, "/results", http.StatusSeeOther)
-			return
-		}
-	}
-
-	// If still no next movie, go to first unvoted movie
-	if nextMovie == nil {
-		for _, movie := range movies {
-			if _, hasVoted := sessionData.Votes[movie.ID]; !hasVoted {
-				nextMovie = &movie
-				break
-			}
+	// Find where the current movie sits in the sequence
+	current := -1
+	for i, movie := range movies {
+		if movie.ID == currentMovieID {
+			current = i
+			break
 		}
 	}
 
-	// If no unvoted movies found, redirect to results
-	if nextMovie == nil {
+	// Find the next movie in the sequence that hasn't been voted on,
+	// wrapping around to any that were skipped
+	next := nextUnvotedIndex(movies, sessionData.Votes, current)
+	if next < 0 {
+		// All movies voted on, redirect to results
 		http.Redirect(w, r, "/results", http.StatusSeeOther)
 		return
 	}
 
 	// Render the movie poll page with the next movie
-	renderMoviePollPage(w, r, movies, sessionData)
+	renderMoviePollPage(w, r, movies, sessionData, next)
 }
 
 // Admin handlers
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/thornzero/movie-poll/types"
+	"github.com/thornzero/movie-poll/views"
+)
+
+// handleLive shows a participant the live vote the host is running
+func (hr *HandlerRegistry) handleLive(w http.ResponseWriter, r *http.Request) {
+	sessionData := Session.GetSessionData(r)
+	if sessionData.UserName == "" {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	views.LivePage(liveParticipantData(sessionData)).Render(r.Context(), w)
+}
+
+// handleLiveStream pushes a participant's view of the live vote to them
+// each time the host moves it on
+func (hr *HandlerRegistry) handleLiveStream(w http.ResponseWriter, r *http.Request) {
+	sessionData := Session.GetSessionData(r)
+	if sessionData.UserName == "" {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	serveEventStream(w, r, func(stream *http.ResponseController) error {
+		var html bytes.Buffer
+		if err := views.LiveStage(liveParticipantData(sessionData)).Render(r.Context(), &html); err != nil {
+			return err
+		}
+		return writeLiveEvent(w, stream, views.LiveEventStage, html.String())
+	}, Live.Updates)
+}
+
+// handleAdminLive shows the live vote host controls
+func (hr *HandlerRegistry) handleAdminLive(w http.ResponseWriter, r *http.Request) {
+	// Check if user is logged in as admin
+	sessionData := Session.GetSessionData(r)
+	if sessionData.AdminUser == nil {
+		http.Redirect(w, r, "/admin", http.StatusSeeOther)
+		return
+	}
+
+	views.AdminLivePage(liveHostData(Live.State())).Render(r.Context(), w)
+}
+
+// handleAdminLiveStream keeps the host panel current as the session moves
+// and as votes come in
+func (hr *HandlerRegistry) handleAdminLiveStream(w http.ResponseWriter, r *http.Request) {
+	// Check if user is logged in as admin
+	sessionData := Session.GetSessionData(r)
+	if sessionData.AdminUser == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	serveEventStream(w, r, func(stream *http.ResponseController) error {
+		var html bytes.Buffer
+		if err := views.LiveHostPanel(liveHostData(Live.State())).Render(r.Context(), &html); err != nil {
+			return err
+		}
+		return writeLiveEvent(w, stream, views.LiveEventHost, html.String())
+	}, Live.Updates, LiveResults)
+}
+
+// handleAdminLiveAction moves the live vote on: open, start, reveal, next,
+// finish or close. Opening puts the current poll slate in the lobby.
+func (hr *HandlerRegistry) handleAdminLiveAction(w http.ResponseWriter, r *http.Request) {
+	// Check if user is logged in as admin
+	sessionData := Session.GetSessionData(r)
+	if sessionData.AdminUser == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var state types.LiveSessionState
+	var err error
+	switch action := chi.URLParam(r, "action"); action {
+	case "open":
+		movies, roundID, loadErr := loadLiveSlate()
+		if errors.Is(loadErr, ErrNoOpenRound) {
+			http.Error(w, loadErr.Error(), http.StatusConflict)
+			return
+		}
+		if loadErr != nil {
+			LogErrorf("Error loading live vote slate: %v", loadErr)
+			http.Error(w, "Failed to load movies", http.StatusInternalServerError)
+			return
+		}
+		state, err = Live.Open(movies, roundID)
+	case "start":
+		state, err = Live.Start()
+	case "reveal":
+		state, err = Live.Reveal()
+	case "next":
+		state, err = Live.Next()
+	case "finish":
+		state, err = Live.Finish()
+	case "close":
+		state, err = Live.Close()
+	default:
+		http.Error(w, "Unknown live vote action", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, ErrInvalidLiveTransition) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	views.LiveHostPanel(liveHostData(state)).Render(r.Context(), w)
+}
+
+// loadLiveSlate returns the movies in the current poll and the round their
+// votes belong to
+func loadLiveSlate() ([]types.Movie, uint, error) {
+	movies, err := DB.GetPollMovies(Config.MovieLimit)
+	if err != nil {
+		return nil, 0, err
+	}
+	round, err := DB.GetCurrentRound()
+	if err != nil || round == nil {
+		return movies, 0, err
+	}
+	return movies, round.ID, nil
+}
+
+// liveParticipantData builds a participant's view of the session, with
+// their vote on the movie on screen and the room's tally during a reveal
+func liveParticipantData(sessionData *SessionData) views.LiveParticipantData {
+	data := views.LiveParticipantData{State: Live.State()}
+	if data.State.Movie == nil {
+		return data
+	}
+
+	votes, err := DB.GetUserVotes(sessionData.UserName, sessionData.VoterDeviceID(), data.State.RoundID)
+	if err != nil {
+		LogErrorf("Error fetching live votes: %v", err)
+	}
+	for _, vote := range votes {
+		if vote.MovieID == data.State.Movie.ID {
+			data.HasVoted = true
+			data.Vote = vote
+		}
+	}
+
+	if data.State.Stage == types.LiveStageReveal {
+		data.Tally = liveTally(data.State)
+	}
+	return data
+}
+
+// liveHostData builds the host's view, with the votes so far on the movie
+// on screen
+func liveHostData(state types.LiveSessionState) views.LiveHostData {
+	data := views.LiveHostData{State: state}
+	if state.Movie != nil {
+		data.Tally = liveTally(state)
+	}
+	return data
+}
+
+// liveTally counts the votes on the movie on screen, logging failures
+func liveTally(state types.LiveSessionState) *types.VotingSummary {
+	tally, err := DB.GetMovieTally(uint(state.Movie.ID), state.RoundID)
+	if err != nil {
+		LogErrorf("Error counting live votes: %v", err)
+		return nil
+	}
+	return tally
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/thornzero/movie-poll/types"
+)
+
+var ErrInvalidLiveTransition = errors.New("live session can't do that right now")
+
+// LiveSession is the host-driven live vote. It lives in memory: a restart
+// simply closes the session, and the votes cast during it are kept as normal
+// votes. Every change is published to Updates.
+type LiveSession struct {
+	mu      sync.Mutex
+	stage   string
+	movies  []types.Movie
+	index   int
+	roundID uint
+
+	Updates *UpdateBroker
+}
+
+func NewLiveSession() *LiveSession {
+	return &LiveSession{
+		stage:   types.LiveStageClosed,
+		Updates: NewUpdateBroker(),
+	}
+}
+
+// State returns where the session is
+func (s *LiveSession) State() types.LiveSessionState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state()
+}
+
+func (s *LiveSession) state() types.LiveSessionState {
+	state := types.LiveSessionState{
+		Stage:      s.stage,
+		MovieIndex: s.index,
+		MovieCount: len(s.movies),
+		RoundID:    s.roundID,
+	}
+	if s.stage == types.LiveStageMovie || s.stage == types.LiveStageReveal {
+		movie := s.movies[s.index]
+		state.Movie = &movie
+	}
+	return state
+}
+
+// Open starts a lobby for the given slate, replacing any running session
+func (s *LiveSession) Open(movies []types.Movie, roundID uint) (types.LiveSessionState, error) {
+	if len(movies) == 0 {
+		return s.State(), ErrInvalidLiveTransition
+	}
+	return s.transition(func() bool {
+		s.stage = types.LiveStageLobby
+		s.movies = movies
+		s.index = 0
+		s.roundID = roundID
+		return true
+	})
+}
+
+// Start puts the first movie on screen
+func (s *LiveSession) Start() (types.LiveSessionState, error) {
+	return s.transition(func() bool {
+		if s.stage != types.LiveStageLobby {
+			return false
+		}
+		s.stage = types.LiveStageMovie
+		s.index = 0
+		return true
+	})
+}
+
+// Reveal shows how the room voted on the movie on screen
+func (s *LiveSession) Reveal() (types.LiveSessionState, error) {
+	return s.transition(func() bool {
+		if s.stage != types.LiveStageMovie {
+			return false
+		}
+		s.stage = types.LiveStageReveal
+		return true
+	})
+}
+
+// Next moves on to the following movie, or finishes after the last one. The
+// host can skip the reveal.
+func (s *LiveSession) Next() (types.LiveSessionState, error) {
+	return s.transition(func() bool {
+		if s.stage != types.LiveStageMovie && s.stage != types.LiveStageReveal {
+			return false
+		}
+		if s.index+1 >= len(s.movies) {
+			s.stage = types.LiveStageDone
+			return true
+		}
+		s.index++
+		s.stage = types.LiveStageMovie
+		return true
+	})
+}
+
+// Finish ends the session early, showing participants the wrap-up
+func (s *LiveSession) Finish() (types.LiveSessionState, error) {
+	return s.transition(func() bool {
+		if s.stage == types.LiveStageClosed || s.stage == types.LiveStageDone {
+			return false
+		}
+		s.stage = types.LiveStageDone
+		return true
+	})
+}
+
+// Close shuts the session down entirely
+func (s *LiveSession) Close() (types.LiveSessionState, error) {
+	return s.transition(func() bool {
+		s.stage = types.LiveStageClosed
+		s.movies = nil
+		s.index = 0
+		s.roundID = 0
+		return true
+	})
+}
+
+// transition applies a change if it's allowed and tells the listeners
+func (s *LiveSession) transition(change func() bool) (types.LiveSessionState, error) {
+	s.mu.Lock()
+	ok := change()
+	state := s.state()
+	s.mu.Unlock()
+
+	if !ok {
+		return state, ErrInvalidLiveTransition
+	}
+	s.Updates.Publish()
+	return state, nil
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/thornzero/movie-poll/types"
+	"github.com/thornzero/movie-poll/views"
+	"gorm.io/gorm"
+)
+
+const mergeHistoryShown = 50
+
+// handleAdminMergePreview shows the identities picked on the users page with
+// the movies where their votes clash
+func (hr *HandlerRegistry) handleAdminMergePreview(w http.ResponseWriter, r *http.Request) {
+	// Check if user is logged in as admin
+	sessionData := Session.GetSessionData(r)
+	if sessionData.AdminUser == nil {
+		http.Redirect(w, r, "/admin", http.StatusSeeOther)
+		return
+	}
+
+	identities, err := parseIdentities(r.URL.Query()["identity"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	preview, err := DB.PreviewIdentityMerge(identities)
+	if err != nil {
+		LogErrorf("Error previewing identity merge: %v", err)
+		http.Error(w, "Failed to load identities", http.StatusInternalServerError)
+		return
+	}
+
+	views.AdminMergePage(*preview).Render(r.Context(), w)
+}
+
+// handleAdminMergeIdentities merges the identities, keeping the vote chosen
+// for each clash
+func (hr *HandlerRegistry) handleAdminMergeIdentities(w http.ResponseWriter, r *http.Request) {
+	// Check if user is logged in as admin
+	sessionData := Session.GetSessionData(r)
+	if sessionData.AdminUser == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+	identities, err := parseIdentities(r.PostForm["identity"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	request := types.MergeRequest{
+		Identities:    identities,
+		CanonicalName: r.FormValue("canonical_name"),
+		DeviceID:      r.FormValue("device_id"),
+		Keep:          make(map[types.MergeKey]int),
+		AdminUser:     sessionData.AdminUser.Username,
+	}
+
+	// Read the kept vote for each clash the preview showed
+	preview, err := DB.PreviewIdentityMerge(identities)
+	if err != nil {
+		LogErrorf("Error previewing identity merge: %v", err)
+		http.Error(w, "Failed to merge identities", http.StatusInternalServerError)
+		return
+	}
+	for _, conflict := range preview.Conflicts {
+		if voteID, err := strconv.Atoi(r.FormValue(views.MergeKeepField(conflict.Key))); err == nil {
+			request.Keep[conflict.Key] = voteID
+		}
+	}
+
+	merge, err := DB.MergeIdentities(request)
+	if errors.Is(err, ErrInvalidMerge) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		LogErrorf("Error merging identities: %v", err)
+		http.Error(w, "Failed to merge identities", http.StatusInternalServerError)
+		return
+	}
+
+	LogInfof("Admin %s merged %d identities into %s", request.AdminUser, len(identities), merge.CanonicalName)
+	views.MergeResult(*merge).Render(r.Context(), w)
+}
+
+// handleAdminMerges shows the merge history with undo buttons
+func (hr *HandlerRegistry) handleAdminMerges(w http.ResponseWriter, r *http.Request) {
+	// Check if user is logged in as admin
+	sessionData := Session.GetSessionData(r)
+	if sessionData.AdminUser == nil {
+		http.Redirect(w, r, "/admin", http.StatusSeeOther)
+		return
+	}
+
+	merges, err := DB.GetIdentityMerges(mergeHistoryShown)
+	if err != nil {
+		LogErrorf("Error fetching identity merges: %v", err)
+		http.Error(w, "Failed to load merges", http.StatusInternalServerError)
+		return
+	}
+
+	views.AdminMergesPage(merges).Render(r.Context(), w)
+}
+
+// handleAdminUndoMerge reverses a merge and returns its updated history row
+func (hr *HandlerRegistry) handleAdminUndoMerge(w http.ResponseWriter, r *http.Request) {
+	// Check if user is logged in as admin
+	sessionData := Session.GetSessionData(r)
+	if sessionData.AdminUser == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	mergeID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid merge ID", http.StatusBadRequest)
+		return
+	}
+
+	merge, err := DB.UndoIdentityMerge(uint(mergeID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "Merge not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, ErrMergeAlreadyUndone) || errors.Is(err, ErrMergeOutdated) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		LogErrorf("Error undoing merge %d: %v", mergeID, err)
+		http.Error(w, "Failed to undo merge", http.StatusInternalServerError)
+		return
+	}
+
+	LogInfof("Admin %s undid the merge into %s", sessionData.AdminUser.Username, merge.CanonicalName)
+	views.MergeRow(*merge).Render(r.Context(), w)
+}
+
+// parseIdentities decodes the identity form values
+func parseIdentities(values []string) ([]types.Identity, error) {
+	identities := make([]types.Identity, 0, len(values))
+	for _, value := range values {
+		identity, err := types.ParseIdentity(value)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+	return identities, nil
+}
@@ -0,0 +1,521 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thornzero/movie-poll/models"
+	"github.com/thornzero/movie-poll/types"
+	"gorm.io/gorm"
+)
+
+// mergeVetoes moves the identities' vetoes to the target. Where several of
+// them vetoed the same movie in a round only one veto stands: the target's
+// own, or else the first one cast. Moved vetoes take the target's free
+// slots. It returns the vetoes as they were, for the merge record, and the
+// ones it touched.
+func mergeVetoes(tx *gorm.DB, identities []types.Identity, target types.Identity) ([]models.IdentityMergeVeto, []models.Veto, error) {
+	var vetoes []models.Veto
+	err := tx.Scopes(scopeIdentities(identities)).Order("created_at, id").Find(&vetoes).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	standing := make(map[types.MergeKey]models.Veto)
+	for _, veto := range vetoes {
+		key := vetoMergeKey(veto)
+		current, ok := standing[key]
+		if !ok || (!isIdentity(current.UserName, current.DeviceID, target) && isIdentity(veto.UserName, veto.DeviceID, target)) {
+			standing[key] = veto
+		}
+	}
+
+	saved := make([]models.IdentityMergeVeto, len(vetoes))
+	for i, veto := range vetoes {
+		saved[i] = models.IdentityMergeVeto{
+			MovieID:       veto.MovieID,
+			UserName:      veto.UserName,
+			DeviceID:      veto.DeviceID,
+			RoundID:       veto.RoundID,
+			Slot:          veto.Slot,
+			VetoCreatedAt: veto.CreatedAt,
+			Kept:          standing[vetoMergeKey(veto)].ID == veto.ID,
+		}
+	}
+
+	// Drop the spare vetoes first so the moved ones find their slots free
+	for i, veto := range vetoes {
+		if !saved[i].Kept {
+			if err := tx.Delete(&veto).Error; err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	for i, veto := range vetoes {
+		if !saved[i].Kept || isIdentity(veto.UserName, veto.DeviceID, target) {
+			continue
+		}
+		var owned []models.Veto
+		err := tx.Where("user_name = ? AND device_id = ?", target.UserName, target.DeviceID).
+			Scopes(scopeRound(derefRoundID(veto.RoundID))).
+			Find(&owned).Error
+		if err != nil {
+			return nil, nil, err
+		}
+		err = tx.Model(&veto).UpdateColumns(map[string]interface{}{
+			"user_name": target.UserName,
+			"device_id": target.DeviceID,
+			"slot":      freeVetoSlot(owned),
+		}).Error
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return saved, vetoes, nil
+}
+
+// mergeBallots moves the identities' ranked ballots to the target, keeping
+// the newest where several of them ranked the same round
+func mergeBallots(tx *gorm.DB, identities []types.Identity, target types.Identity) ([]models.IdentityMergeBallot, error) {
+	var ballots []models.Ballot
+	err := tx.Preload("Entries").Scopes(scopeIdentities(identities)).Order("updated_at DESC, id DESC").Find(&ballots).Error
+	if err != nil {
+		return nil, err
+	}
+
+	standing := make(map[uint]uint) // round ID -> ballot kept for it
+	for _, ballot := range ballots {
+		if _, ok := standing[derefRoundID(ballot.RoundID)]; !ok {
+			standing[derefRoundID(ballot.RoundID)] = ballot.ID
+		}
+	}
+
+	saved := make([]models.IdentityMergeBallot, len(ballots))
+	for i, ballot := range ballots {
+		saved[i] = models.IdentityMergeBallot{
+			UserName:        ballot.UserName,
+			DeviceID:        ballot.DeviceID,
+			RoundID:         ballot.RoundID,
+			Ranking:         formatRanking(ballot.RankedMovieIDs()),
+			BallotCreatedAt: ballot.CreatedAt,
+			BallotUpdatedAt: ballot.UpdatedAt,
+			Kept:            standing[derefRoundID(ballot.RoundID)] == ballot.ID,
+		}
+
+		if !saved[i].Kept {
+			if err := deleteBallot(tx, ballot); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if isIdentity(ballot.UserName, ballot.DeviceID, target) {
+			continue
+		}
+		err := tx.Model(&ballot).UpdateColumns(map[string]interface{}{
+			"user_name": target.UserName,
+			"device_id": target.DeviceID,
+		}).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	return saved, nil
+}
+
+// mergeRSVPs moves the identities' RSVPs to the target, keeping the newest
+// where several of them answered for the same event
+func mergeRSVPs(tx *gorm.DB, identities []types.Identity, target types.Identity) ([]models.IdentityMergeRSVP, error) {
+	var rsvps []models.EventRSVP
+	err := tx.Scopes(scopeIdentities(identities)).Order("updated_at DESC, id DESC").Find(&rsvps).Error
+	if err != nil {
+		return nil, err
+	}
+
+	standing := make(map[uint]uint) // event ID -> RSVP kept for it
+	for _, rsvp := range rsvps {
+		if _, ok := standing[rsvp.EventID]; !ok {
+			standing[rsvp.EventID] = rsvp.ID
+		}
+	}
+
+	saved := make([]models.IdentityMergeRSVP, len(rsvps))
+	for i, rsvp := range rsvps {
+		saved[i] = models.IdentityMergeRSVP{
+			EventID:       rsvp.EventID,
+			UserName:      rsvp.UserName,
+			DeviceID:      rsvp.DeviceID,
+			Status:        rsvp.Status,
+			RSVPCreatedAt: rsvp.CreatedAt,
+			RSVPUpdatedAt: rsvp.UpdatedAt,
+			Kept:          standing[rsvp.EventID] == rsvp.ID,
+		}
+	}
+
+	// Drop the stale RSVPs first; there's one per voter and event
+	for i, rsvp := range rsvps {
+		if !saved[i].Kept {
+			if err := tx.Delete(&rsvp).Error; err != nil {
+				return nil, err
+			}
+		}
+	}
+	for i, rsvp := range rsvps {
+		if !saved[i].Kept || isIdentity(rsvp.UserName, rsvp.DeviceID, target) {
+			continue
+		}
+		err := tx.Model(&rsvp).UpdateColumns(map[string]interface{}{
+			"user_name": target.UserName,
+			"device_id": target.DeviceID,
+		}).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	return saved, nil
+}
+
+// mergeNominations moves the identities' nominations to the target
+func mergeNominations(tx *gorm.DB, identities []types.Identity, target types.Identity) ([]models.IdentityMergeNomination, error) {
+	var nominations []models.Nomination
+	if err := tx.Scopes(scopeIdentities(identities)).Order("id").Find(&nominations).Error; err != nil {
+		return nil, err
+	}
+
+	var saved []models.IdentityMergeNomination
+	for _, nomination := range nominations {
+		if isIdentity(nomination.UserName, nomination.DeviceID, target) {
+			continue
+		}
+		saved = append(saved, models.IdentityMergeNomination{
+			NominationID: nomination.ID,
+			UserName:     nomination.UserName,
+			DeviceID:     nomination.DeviceID,
+		})
+		err := tx.Model(&nomination).UpdateColumns(map[string]interface{}{
+			"user_name": target.UserName,
+			"device_id": target.DeviceID,
+		}).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	return saved, nil
+}
+
+// linkMergedDevices links every merged device but the target's to it, so
+// they go on voting as the merged identity. It returns the links as they
+// were, including those of devices linked to a merged device, which follow
+// it.
+func linkMergedDevices(tx *gorm.DB, deviceIDs []string, targetDeviceID string) ([]models.IdentityMergeLink, error) {
+	var saved []models.IdentityMergeLink
+	devices := NewDeviceService(tx)
+	for _, deviceID := range deviceIDs {
+		if deviceID == targetDeviceID {
+			continue
+		}
+
+		var existing []models.Device
+		if err := tx.Where("device_id = ? OR linked_to = ?", deviceID, deviceID).Find(&existing).Error; err != nil {
+			return nil, err
+		}
+		ownRow := false
+		for _, device := range existing {
+			ownRow = ownRow || device.DeviceID == deviceID
+			saved = append(saved, models.IdentityMergeLink{DeviceID: device.DeviceID, LinkedTo: device.LinkedTo})
+		}
+		if !ownRow {
+			saved = append(saved, models.IdentityMergeLink{DeviceID: deviceID})
+		}
+
+		if err := devices.LinkDevice(deviceID, targetDeviceID); err != nil {
+			return nil, err
+		}
+	}
+	return saved, nil
+}
+
+// restoreVetoes puts back the vetoes a merge changed, clearing whatever the
+// identities now hold on the same movies. It returns every veto it touched.
+func restoreVetoes(tx *gorm.DB, merge models.IdentityMerge, identities []types.Identity) ([]models.Veto, error) {
+	touched := make(map[types.MergeKey]bool)
+	originals := make([]models.Veto, len(merge.Vetoes))
+	for i, saved := range merge.Vetoes {
+		originals[i] = models.Veto{
+			MovieID:   saved.MovieID,
+			UserName:  saved.UserName,
+			DeviceID:  saved.DeviceID,
+			RoundID:   saved.RoundID,
+			Slot:      saved.Slot,
+			CreatedAt: saved.VetoCreatedAt,
+		}
+		touched[vetoMergeKey(originals[i])] = true
+	}
+
+	var current []models.Veto
+	if err := tx.Scopes(scopeIdentities(identities)).Find(&current).Error; err != nil {
+		return nil, err
+	}
+	var replaced []models.Veto
+	for _, veto := range current {
+		if !touched[vetoMergeKey(veto)] {
+			continue
+		}
+		if err := tx.Delete(&veto).Error; err != nil {
+			return nil, err
+		}
+		replaced = append(replaced, veto)
+	}
+
+	for i := range originals {
+		if err := tx.Create(&originals[i]).Error; err != nil {
+			return nil, err
+		}
+	}
+	return append(replaced, originals...), nil
+}
+
+// restoreBallots puts back the ranked ballots a merge changed, clearing
+// whatever the identities now hold for the same rounds
+func restoreBallots(tx *gorm.DB, merge models.IdentityMerge, identities []types.Identity) error {
+	touched := make(map[uint]bool)
+	for _, saved := range merge.Ballots {
+		touched[derefRoundID(saved.RoundID)] = true
+	}
+
+	var current []models.Ballot
+	if err := tx.Scopes(scopeIdentities(identities)).Find(&current).Error; err != nil {
+		return err
+	}
+	for _, ballot := range current {
+		if touched[derefRoundID(ballot.RoundID)] {
+			if err := deleteBallot(tx, ballot); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, saved := range merge.Ballots {
+		ballot := models.Ballot{
+			UserName:  saved.UserName,
+			DeviceID:  saved.DeviceID,
+			RoundID:   saved.RoundID,
+			CreatedAt: saved.BallotCreatedAt,
+			UpdatedAt: saved.BallotUpdatedAt,
+		}
+		for i, movieID := range parseRanking(saved.Ranking) {
+			ballot.Entries = append(ballot.Entries, models.BallotEntry{MovieID: movieID, Rank: i + 1})
+		}
+		if err := tx.Create(&ballot).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreRSVPs puts back the RSVPs a merge changed, clearing whatever the
+// identities now hold for the same events
+func restoreRSVPs(tx *gorm.DB, merge models.IdentityMerge, identities []types.Identity) error {
+	touched := make(map[uint]bool)
+	for _, saved := range merge.RSVPs {
+		touched[saved.EventID] = true
+	}
+
+	var current []models.EventRSVP
+	if err := tx.Scopes(scopeIdentities(identities)).Find(&current).Error; err != nil {
+		return err
+	}
+	for _, rsvp := range current {
+		if touched[rsvp.EventID] {
+			if err := tx.Delete(&rsvp).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, saved := range merge.RSVPs {
+		err := tx.Create(&models.EventRSVP{
+			EventID:   saved.EventID,
+			UserName:  saved.UserName,
+			DeviceID:  saved.DeviceID,
+			Status:    saved.Status,
+			CreatedAt: saved.RSVPCreatedAt,
+			UpdatedAt: saved.RSVPUpdatedAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreNominations hands the nominations a merge moved back to whoever
+// made them
+func restoreNominations(tx *gorm.DB, merge models.IdentityMerge) error {
+	for _, saved := range merge.Nominations {
+		err := tx.Model(&models.Nomination{}).Where("id = ?", saved.NominationID).
+			UpdateColumns(map[string]interface{}{
+				"user_name": saved.UserName,
+				"device_id": saved.DeviceID,
+			}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreLinks puts the device links a merge changed back as they were
+func restoreLinks(tx *gorm.DB, merge models.IdentityMerge) error {
+	for _, saved := range merge.Links {
+		err := tx.Model(&models.Device{}).Where("device_id = ?", saved.DeviceID).
+			Update("linked_to", saved.LinkedTo).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeChangedSince reports whether any of the identities has voted, vetoed,
+// ranked or RSVPed since the given time, or been merged again since the
+// given merge, so that undoing it would throw later changes away
+func mergeChangedSince(tx *gorm.DB, merge models.IdentityMerge, identities []types.Identity) (bool, error) {
+	changes := []struct {
+		model  interface{}
+		column string
+	}{
+		{&models.VoteRevision{}, "created_at"},
+		{&models.Veto{}, "created_at"},
+		{&models.Ballot{}, "updated_at"},
+		{&models.EventRSVP{}, "updated_at"},
+	}
+	for _, change := range changes {
+		var count int64
+		err := tx.Model(change.model).Scopes(scopeIdentities(identities)).
+			Where(change.column+" > ?", merge.CreatedAt).
+			Count(&count).Error
+		if err != nil || count > 0 {
+			return count > 0, err
+		}
+	}
+
+	var later []models.IdentityMerge
+	err := preloadMergeRecords(tx).Where("id > ? AND undone_at IS NULL", merge.ID).Find(&later).Error
+	if err != nil {
+		return false, err
+	}
+	involved := make(map[types.Identity]bool)
+	for _, identity := range identities {
+		involved[identity] = true
+	}
+	for _, other := range later {
+		for _, identity := range mergedIdentities(other) {
+			if involved[identity] {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// preloadMergeRecords loads a merge with everything it recorded
+func preloadMergeRecords(tx *gorm.DB) *gorm.DB {
+	return tx.Preload("Votes").Preload("Vetoes").Preload("Ballots").Preload("RSVPs").
+		Preload("Nominations").Preload("Names").Preload("Links")
+}
+
+// mergedIdentities lists every identity a merge took records from, along
+// with the identity it merged them into
+func mergedIdentities(merge models.IdentityMerge) []types.Identity {
+	identities := []types.Identity{{UserName: merge.CanonicalName, DeviceID: merge.DeviceID}}
+	for _, saved := range merge.Votes {
+		identities = append(identities, types.Identity{UserName: saved.UserName, DeviceID: saved.DeviceID})
+	}
+	for _, saved := range merge.Vetoes {
+		identities = append(identities, types.Identity{UserName: saved.UserName, DeviceID: saved.DeviceID})
+	}
+	for _, saved := range merge.Ballots {
+		identities = append(identities, types.Identity{UserName: saved.UserName, DeviceID: saved.DeviceID})
+	}
+	for _, saved := range merge.RSVPs {
+		identities = append(identities, types.Identity{UserName: saved.UserName, DeviceID: saved.DeviceID})
+	}
+	for _, saved := range merge.Nominations {
+		identities = append(identities, types.Identity{UserName: saved.UserName, DeviceID: saved.DeviceID})
+	}
+	return uniqueIdentities(identities)
+}
+
+// identityHasRecords reports whether the identity has cast anything a merge
+// would move
+func identityHasRecords(tx *gorm.DB, identity types.Identity) (bool, error) {
+	for _, model := range []interface{}{&models.Vote{}, &models.Veto{}, &models.Ballot{}, &models.EventRSVP{}, &models.Nomination{}} {
+		var count int64
+		err := tx.Model(model).Where("user_name = ? AND device_id = ?", identity.UserName, identity.DeviceID).
+			Count(&count).Error
+		if err != nil || count > 0 {
+			return count > 0, err
+		}
+	}
+	return false, nil
+}
+
+// bumpMergeGeneration marks that identities have changed, so sessions
+// reload the name and votes they cached
+func bumpMergeGeneration(tx *gorm.DB) error {
+	return NewSettingService(tx).SetSetting(SettingMergeGeneration, strconv.FormatInt(time.Now().UnixNano(), 10))
+}
+
+// scopeIdentities restricts a query on a table with user_name and device_id
+// columns to rows belonging to any of the identities
+func scopeIdentities(identities []types.Identity) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(identities) == 0 {
+			return db.Where("1 = 0")
+		}
+		conditions := make([]string, len(identities))
+		args := make([]interface{}, 0, 2*len(identities))
+		for i, identity := range identities {
+			conditions[i] = "(user_name = ? AND device_id = ?)"
+			args = append(args, identity.UserName, identity.DeviceID)
+		}
+		return db.Where(strings.Join(conditions, " OR "), args...)
+	}
+}
+
+// deleteBallot removes a ballot along with its entries
+func deleteBallot(tx *gorm.DB, ballot models.Ballot) error {
+	if err := tx.Where("ballot_id = ?", ballot.ID).Delete(&models.BallotEntry{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&ballot).Error
+}
+
+func isIdentity(userName, deviceID string, identity types.Identity) bool {
+	return userName == identity.UserName && deviceID == identity.DeviceID
+}
+
+func vetoMergeKey(veto models.Veto) types.MergeKey {
+	return types.MergeKey{MovieID: int(veto.MovieID), RoundID: int(derefRoundID(veto.RoundID))}
+}
+
+// formatRanking and parseRanking convert a ballot's ranking to and from the
+// form a merge records it in
+func formatRanking(movieIDs []uint) string {
+	parts := make([]string, len(movieIDs))
+	for i, movieID := range movieIDs {
+		parts[i] = strconv.FormatUint(uint64(movieID), 10)
+	}
+	return strings.Join(parts, ",")
+}
+
+func parseRanking(ranking string) []uint {
+	var movieIDs []uint
+	for _, part := range strings.Split(ranking, ",") {
+		if movieID, err := strconv.ParseUint(part, 10, 64); err == nil {
+			movieIDs = append(movieIDs, uint(movieID))
+		}
+	}
+	return movieIDs
+}
@@ -0,0 +1,542 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thornzero/movie-poll/models"
+	"github.com/thornzero/movie-poll/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Revision sources for votes moved by an identity merge
+const (
+	RevisionSourceMerge     = "merge"
+	RevisionSourceMergeUndo = "merge-undo"
+)
+
+var (
+	ErrInvalidMerge       = errors.New("invalid identity merge")
+	ErrMergeAlreadyUndone = errors.New("merge has already been undone")
+	ErrMergeOutdated      = errors.New("merged voters have changed things since the merge, so it can no longer be undone")
+)
+
+type MergeService struct {
+	db *gorm.DB
+}
+
+func NewMergeService(db *gorm.DB) *MergeService {
+	return &MergeService{db: db}
+}
+
+// PreviewMerge counts each identity's votes and finds the movies more than
+// one of them voted on
+func (s *MergeService) PreviewMerge(identities []types.Identity) (*types.MergePreview, error) {
+	identities = uniqueIdentities(identities)
+	votes, err := identityVotes(s.db, identities)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &types.MergePreview{}
+	counts := make(map[types.Identity]int)
+	for _, vote := range votes {
+		counts[types.Identity{UserName: vote.UserName, DeviceID: vote.DeviceID}]++
+	}
+	for _, identity := range identities {
+		preview.Identities = append(preview.Identities, types.IdentitySummary{
+			Identity:  identity,
+			VoteCount: counts[identity],
+		})
+	}
+
+	groups, order := groupMergeVotes(votes)
+	for _, key := range order {
+		if len(groups[key]) < 2 {
+			continue
+		}
+		conflict := types.MergeConflict{Key: key}
+		var movie models.Movie
+		if err := s.db.Select("title").Where("id = ?", key.MovieID).Limit(1).Find(&movie).Error; err != nil {
+			return nil, err
+		}
+		conflict.Title = movie.Title
+		for _, vote := range groups[key] {
+			conflict.Votes = append(conflict.Votes, convertGORMVoteToType(vote))
+		}
+		// Newest vote first, as the likeliest one to keep
+		sort.SliceStable(conflict.Votes, func(i, j int) bool {
+			return conflict.Votes[i].UpdatedAt > conflict.Votes[j].UpdatedAt
+		})
+		preview.Conflicts = append(preview.Conflicts, conflict)
+	}
+	sort.SliceStable(preview.Conflicts, func(i, j int) bool {
+		return preview.Conflicts[i].Title < preview.Conflicts[j].Title
+	})
+	return preview, nil
+}
+
+// MergeIdentities moves every vote of the given identities to the canonical
+// name on the chosen device and renames their device names to match. Where
+// several identities voted on the same movie in a round, only the vote named
+// in Keep survives, or the newest one if Keep doesn't name any. Their vetoes,
+// ballots, RSVPs and nominations move too, and the other devices are linked
+// to the chosen one so they go on voting as the merged identity. The merge
+// is recorded so it can be undone.
+func (s *MergeService) MergeIdentities(request types.MergeRequest) (*models.IdentityMerge, error) {
+	canonical := strings.TrimSpace(request.CanonicalName)
+	target := types.Identity{UserName: canonical, DeviceID: request.DeviceID}
+	identities := uniqueIdentities(request.Identities)
+	if len(identities) < 2 {
+		return nil, fmt.Errorf("%w: pick at least two identities", ErrInvalidMerge)
+	}
+	if canonical == "" {
+		return nil, fmt.Errorf("%w: the merged name can't be blank", ErrInvalidMerge)
+	}
+	devices := identityDevices(identities)
+	if _, ok := devices[target.DeviceID]; !ok {
+		return nil, fmt.Errorf("%w: the votes must move to one of the merged devices", ErrInvalidMerge)
+	}
+	// Votes already under the merged identity take part like any other
+	identities = uniqueIdentities(append(identities, target))
+
+	merge := models.IdentityMerge{
+		CanonicalName: canonical,
+		DeviceID:      target.DeviceID,
+		AdminUser:     request.AdminUser,
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		votes, err := identityVotes(tx, identities)
+		if err != nil {
+			return err
+		}
+
+		// Settle which vote survives on each movie
+		kept := make(map[uint]bool)
+		groups, order := groupMergeVotes(votes)
+		for _, key := range order {
+			group := groups[key]
+			if len(group) == 1 {
+				kept[group[0].ID] = true
+				continue
+			}
+			choice, chosen := request.Keep[key]
+			if !chosen {
+				// A clash nobody chose for, like one with a vote already under
+				// the merged name, keeps the newest vote
+				kept[newestVote(group).ID] = true
+				continue
+			}
+			found := false
+			for _, vote := range group {
+				if vote.ID == uint(choice) {
+					kept[vote.ID], found = true, true
+				}
+			}
+			if !found {
+				return fmt.Errorf("%w: the vote kept for movie %d isn't one of its votes", ErrInvalidMerge, key.MovieID)
+			}
+		}
+
+		for _, vote := range votes {
+			merge.Votes = append(merge.Votes, models.IdentityMergeVote{
+				MovieID:       vote.MovieID,
+				UserName:      vote.UserName,
+				DeviceID:      vote.DeviceID,
+				RoundID:       vote.RoundID,
+				Vibe:          vote.Vibe,
+				Seen:          vote.Seen,
+				VoteCreatedAt: vote.CreatedAt,
+				VoteUpdatedAt: vote.UpdatedAt,
+				Kept:          kept[vote.ID],
+			})
+		}
+		names, err := mergeDeviceNames(tx, identities, canonical)
+		if err != nil {
+			return err
+		}
+		merge.Names = names
+
+		// Move the kept votes and drop the rest, keeping the history straight
+		for i := range votes {
+			vote := votes[i]
+			if vote.UserName == target.UserName && vote.DeviceID == target.DeviceID && kept[vote.ID] {
+				continue
+			}
+			if err := recordVoteRevision(tx, &vote, nil, RevisionSourceMerge); err != nil {
+				return err
+			}
+			if !kept[vote.ID] {
+				if err := tx.Delete(&vote).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			moved := vote
+			moved.UserName, moved.DeviceID = target.UserName, target.DeviceID
+			err := tx.Model(&vote).UpdateColumns(map[string]interface{}{
+				"user_name": moved.UserName,
+				"device_id": moved.DeviceID,
+			}).Error
+			if err != nil {
+				return err
+			}
+			if err := recordVoteRevision(tx, nil, &moved, RevisionSourceMerge); err != nil {
+				return err
+			}
+		}
+
+		// One user row per device: the target takes the merged name and the
+		// other devices' rows go with their votes
+		for deviceID, userNames := range devices {
+			if deviceID == target.DeviceID {
+				err = tx.Model(&models.User{}).Where("device_id = ?", deviceID).
+					Update("user_name", target.UserName).Error
+			} else {
+				err = tx.Where("device_id = ? AND user_name IN ?", deviceID, userNames).
+					Delete(&models.User{}).Error
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if err := NewUserService(tx).UpdateUserStats(target.UserName, target.DeviceID); err != nil {
+			return err
+		}
+
+		var vetoes []models.Veto
+		if merge.Vetoes, vetoes, err = mergeVetoes(tx, identities, target); err != nil {
+			return err
+		}
+		if merge.Ballots, err = mergeBallots(tx, identities, target); err != nil {
+			return err
+		}
+		if merge.RSVPs, err = mergeRSVPs(tx, identities, target); err != nil {
+			return err
+		}
+		if merge.Nominations, err = mergeNominations(tx, identities, target); err != nil {
+			return err
+		}
+		deviceIDs := make([]string, 0, len(devices))
+		for deviceID := range devices {
+			deviceIDs = append(deviceIDs, deviceID)
+		}
+		sort.Strings(deviceIDs)
+		if merge.Links, err = linkMergedDevices(tx, deviceIDs, target.DeviceID); err != nil {
+			return err
+		}
+
+		// Recorded last, so that everything the merge changed predates it and
+		// anything newer is a later change UndoMerge mustn't clobber
+		if err := tx.Create(&merge).Error; err != nil {
+			return err
+		}
+		if err := bumpMergeGeneration(tx); err != nil {
+			return err
+		}
+		if err := refreshVoteAppeals(tx, votes); err != nil {
+			return err
+		}
+		return refreshVetoAppeals(tx, vetoes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &merge, nil
+}
+
+// UndoMerge puts back the votes, vetoes, ballots, RSVPs, nominations,
+// device names and device links a merge changed. The merged identity's
+// records on the affected movies, rounds and events are replaced by the
+// originals. It returns ErrMergeOutdated rather than throw away anything the
+// voters involved have done since, including being merged again.
+func (s *MergeService) UndoMerge(mergeID uint) (*models.IdentityMerge, error) {
+	var merge models.IdentityMerge
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := preloadMergeRecords(tx).First(&merge, mergeID).Error; err != nil {
+			return err
+		}
+		if merge.IsUndone() {
+			return ErrMergeAlreadyUndone
+		}
+		identities := mergedIdentities(merge)
+		changed, err := mergeChangedSince(tx, merge, identities)
+		if err != nil {
+			return err
+		}
+		if changed {
+			return ErrMergeOutdated
+		}
+
+		// Clear whatever now sits where the original votes go back
+		originals := make([]models.Vote, len(merge.Votes))
+		var restored []types.Identity
+		for i, saved := range merge.Votes {
+			originals[i] = models.Vote{
+				MovieID:   saved.MovieID,
+				UserName:  saved.UserName,
+				DeviceID:  saved.DeviceID,
+				RoundID:   saved.RoundID,
+				Vibe:      saved.Vibe,
+				Seen:      saved.Seen,
+				CreatedAt: saved.VoteCreatedAt,
+				UpdatedAt: saved.VoteUpdatedAt,
+			}
+			restored = append(restored, types.Identity{UserName: saved.UserName, DeviceID: saved.DeviceID})
+		}
+		restored = uniqueIdentities(restored)
+		merged := types.Identity{UserName: merge.CanonicalName, DeviceID: merge.DeviceID}
+		current, err := identityVotes(tx, uniqueIdentities(append([]types.Identity{merged}, restored...)))
+		if err != nil {
+			return err
+		}
+		touched := make(map[types.MergeKey]bool)
+		for _, vote := range originals {
+			touched[mergeKey(vote)] = true
+		}
+		var replaced []models.Vote
+		for _, vote := range current {
+			if touched[mergeKey(vote)] {
+				replaced = append(replaced, vote)
+			}
+		}
+		if err := recordVoteDeletions(tx, replaced, RevisionSourceMergeUndo); err != nil {
+			return err
+		}
+		for _, vote := range replaced {
+			if err := tx.Delete(&vote).Error; err != nil {
+				return err
+			}
+		}
+
+		for i := range originals {
+			if err := tx.Create(&originals[i]).Error; err != nil {
+				return err
+			}
+			if err := recordVoteRevision(tx, nil, &originals[i], RevisionSourceMergeUndo); err != nil {
+				return err
+			}
+		}
+
+		if err := restoreDeviceNames(tx, merge); err != nil {
+			return err
+		}
+		vetoes, err := restoreVetoes(tx, merge, identities)
+		if err != nil {
+			return err
+		}
+		if err := restoreBallots(tx, merge, identities); err != nil {
+			return err
+		}
+		if err := restoreRSVPs(tx, merge, identities); err != nil {
+			return err
+		}
+		if err := restoreNominations(tx, merge); err != nil {
+			return err
+		}
+		if err := restoreLinks(tx, merge); err != nil {
+			return err
+		}
+
+		// Give each device's user row back its old name and counts
+		users := NewUserService(tx)
+		for _, identity := range restored {
+			err := tx.Model(&models.User{}).
+				Where("device_id = ?", identity.DeviceID).
+				Update("user_name", identity.UserName).Error
+			if err != nil {
+				return err
+			}
+			if err := users.UpdateUserStats(identity.UserName, identity.DeviceID); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		merge.UndoneAt = &now
+		if err := tx.Model(&merge).Update("undone_at", now).Error; err != nil {
+			return err
+		}
+		if err := bumpMergeGeneration(tx); err != nil {
+			return err
+		}
+		if err := refreshVoteAppeals(tx, append(replaced, originals...)); err != nil {
+			return err
+		}
+		return refreshVetoAppeals(tx, vetoes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &merge, nil
+}
+
+// GetMerges lists merges, newest first, with the records they involved
+func (s *MergeService) GetMerges(limit int) ([]models.IdentityMerge, error) {
+	var merges []models.IdentityMerge
+	query := preloadMergeRecords(s.db).Order("created_at DESC, id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&merges).Error
+	return merges, err
+}
+
+// newestVote returns the most recently updated of the votes
+func newestVote(votes []models.Vote) models.Vote {
+	newest := votes[0]
+	for _, vote := range votes[1:] {
+		if vote.UpdatedAt.After(newest.UpdatedAt) {
+			newest = vote
+		}
+	}
+	return newest
+}
+
+// mergeDeviceNames renames the merged identities' names on their devices to
+// the canonical one, returning what changed
+func mergeDeviceNames(tx *gorm.DB, identities []types.Identity, canonical string) ([]models.IdentityMergeName, error) {
+	var changes []models.IdentityMergeName
+	now := time.Now()
+	for deviceID, userNames := range identityDevices(identities) {
+		var device models.Device
+		if err := tx.Where("device_id = ?", deviceID).Limit(1).Find(&device).Error; err != nil {
+			return nil, err
+		}
+
+		var existing []models.DeviceName
+		if err := tx.Where("device_id = ? AND name IN ?", deviceID, append(userNames, canonical)).Find(&existing).Error; err != nil {
+			return nil, err
+		}
+		hasCanonical, renamedLast := false, false
+		for _, name := range existing {
+			if name.Name == canonical {
+				hasCanonical = true
+				continue
+			}
+			wasLast := device.ID != 0 && device.LastName == name.Name
+			renamedLast = renamedLast || wasLast
+			changes = append(changes, models.IdentityMergeName{
+				DeviceID:    deviceID,
+				Name:        name.Name,
+				WasLastName: wasLast,
+			})
+			if err := tx.Delete(&name).Error; err != nil {
+				return nil, err
+			}
+		}
+
+		if !hasCanonical {
+			err := tx.Create(&models.DeviceName{
+				DeviceID:  deviceID,
+				Name:      canonical,
+				FirstUsed: now,
+				LastUsed:  now,
+			}).Error
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, models.IdentityMergeName{DeviceID: deviceID, Name: canonical, Added: true})
+		}
+		if renamedLast {
+			if err := tx.Model(&device).Update("last_name", canonical).Error; err != nil {
+				return nil, err
+			}
+		}
+	}
+	return changes, nil
+}
+
+// restoreDeviceNames reverses mergeDeviceNames
+func restoreDeviceNames(tx *gorm.DB, merge models.IdentityMerge) error {
+	now := time.Now()
+	for _, change := range merge.Names {
+		if change.Added {
+			err := tx.Where("device_id = ? AND name = ?", change.DeviceID, change.Name).
+				Delete(&models.DeviceName{}).Error
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.DeviceName{
+			DeviceID:  change.DeviceID,
+			Name:      change.Name,
+			FirstUsed: now,
+			LastUsed:  now,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if change.WasLastName {
+			err := tx.Model(&models.Device{}).Where("device_id = ?", change.DeviceID).
+				Update("last_name", change.Name).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// identityVotes loads every vote cast by any of the identities
+func identityVotes(tx *gorm.DB, identities []types.Identity) ([]models.Vote, error) {
+	var votes []models.Vote
+	for _, identity := range identities {
+		var found []models.Vote
+		err := tx.Where("user_name = ? AND device_id = ?", identity.UserName, identity.DeviceID).
+			Order("updated_at DESC, id DESC").
+			Find(&found).Error
+		if err != nil {
+			return nil, err
+		}
+		votes = append(votes, found...)
+	}
+	return votes, nil
+}
+
+// groupMergeVotes buckets votes by movie and round, keeping the order each
+// bucket was first seen in
+func groupMergeVotes(votes []models.Vote) (map[types.MergeKey][]models.Vote, []types.MergeKey) {
+	groups := make(map[types.MergeKey][]models.Vote)
+	var order []types.MergeKey
+	for _, vote := range votes {
+		key := mergeKey(vote)
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], vote)
+	}
+	return groups, order
+}
+
+func mergeKey(vote models.Vote) types.MergeKey {
+	return types.MergeKey{MovieID: int(vote.MovieID), RoundID: int(derefRoundID(vote.RoundID))}
+}
+
+// uniqueIdentities drops repeats, keeping the first occurrence
+func uniqueIdentities(identities []types.Identity) []types.Identity {
+	seen := make(map[types.Identity]bool)
+	var unique []types.Identity
+	for _, identity := range identities {
+		if !seen[identity] {
+			seen[identity] = true
+			unique = append(unique, identity)
+		}
+	}
+	return unique
+}
+
+// identityDevices maps each device to the names it appears under
+func identityDevices(identities []types.Identity) map[string][]string {
+	devices := make(map[string][]string)
+	for _, identity := range identities {
+		devices[identity.DeviceID] = append(devices[identity.DeviceID], identity.UserName)
+	}
+	return devices
+}
@@ -34,6 +34,25 @@ func (s *MovieService) GetMovies(limit int) ([]types.Movie, error) {
 	return result, nil
 }
 
+// GetUnscreenedMovies returns the movies that haven't been shown at the tavern yet
+func (s *MovieService) GetUnscreenedMovies(limit int) ([]types.Movie, error) {
+	var movies []models.Movie
+	query := s.db.Where("id NOT IN (?)", s.db.Model(&models.Screening{}).Select("movie_id")).Order("title")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&movies).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var result []types.Movie
+	for _, movie := range movies {
+		result = append(result, convertGORMMovieToType(movie))
+	}
+	return result, nil
+}
+
 // GetMovieByID - replaces 20+ line GetMovieByID function
 func (s *MovieService) GetMovieByID(id uint) (*types.Movie, error) {
 	var movie models.Movie
@@ -70,7 +89,106 @@ func (s *MovieService) UpdateMovie(movie *types.Movie) error {
 
 // DeleteMovie - replaces 5+ line DeleteMovie function
 func (s *MovieService) DeleteMovie(id uint) error {
-	return s.db.Delete(&models.Movie{}, id).Error
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("movie_id = ?", id).Delete(&models.Appeal{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("movie_id = ?", id).Delete(&models.AppealSnapshotEntry{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Movie{}, id).Error
+	})
+}
+
+// MergeDuplicateMovie folds a duplicate movie into the one being kept before
+// deleting it. Its votes, vote history and vetoes move across; where someone
+// voted on both in the same round the newer vote stays, and a veto on both
+// counts once.
+func (s *MovieService) MergeDuplicateMovie(keepID, duplicateID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		rounds := make(map[uint]bool)
+
+		var votes []models.Vote
+		if err := tx.Where("movie_id = ?", duplicateID).Find(&votes).Error; err != nil {
+			return err
+		}
+		for i := range votes {
+			vote := votes[i]
+			roundID := derefRoundID(vote.RoundID)
+			rounds[roundID] = true
+
+			var kept []models.Vote
+			err := tx.Where("movie_id = ? AND user_name = ? AND device_id = ?", keepID, vote.UserName, vote.DeviceID).
+				Scopes(scopeRound(roundID)).
+				Find(&kept).Error
+			if err != nil {
+				return err
+			}
+			if len(kept) > 0 && !vote.UpdatedAt.After(kept[0].UpdatedAt) {
+				if err := recordVoteDeletions(tx, []models.Vote{vote}, RevisionSourceDuplicate); err != nil {
+					return err
+				}
+				if err := tx.Delete(&vote).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if len(kept) > 0 {
+				if err := recordVoteDeletions(tx, kept, RevisionSourceDuplicate); err != nil {
+					return err
+				}
+				if err := tx.Delete(&kept[0]).Error; err != nil {
+					return err
+				}
+			}
+			if err := tx.Model(&vote).UpdateColumn("movie_id", keepID).Error; err != nil {
+				return err
+			}
+		}
+
+		// The duplicate's history now belongs to the kept movie
+		err := tx.Model(&models.VoteRevision{}).Where("movie_id = ?", duplicateID).
+			UpdateColumn("movie_id", keepID).Error
+		if err != nil {
+			return err
+		}
+
+		var vetoes []models.Veto
+		if err := tx.Where("movie_id = ?", duplicateID).Find(&vetoes).Error; err != nil {
+			return err
+		}
+		for _, veto := range vetoes {
+			roundID := derefRoundID(veto.RoundID)
+			rounds[roundID] = true
+
+			var taken int64
+			err := tx.Model(&models.Veto{}).
+				Where("movie_id = ? AND user_name = ? AND device_id = ?", keepID, veto.UserName, veto.DeviceID).
+				Scopes(scopeRound(roundID)).
+				Count(&taken).Error
+			if err != nil {
+				return err
+			}
+			if taken > 0 {
+				err = tx.Delete(&veto).Error
+			} else {
+				err = tx.Model(&veto).UpdateColumn("movie_id", keepID).Error
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := NewMovieService(tx).DeleteMovie(duplicateID); err != nil {
+			return err
+		}
+		for roundID := range rounds {
+			if err := refreshMovieAppeal(tx, keepID, roundID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 // SearchMovies - new functionality with local search
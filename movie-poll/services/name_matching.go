@@ -0,0 +1,295 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/thornzero/movie-poll/types"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	minNameMatchScore = 0.8 // below this a name isn't worth suggesting
+	maxNameMatches    = 5
+	nicknameScore     = 0.9
+)
+
+// nicknameGroups lists names that are commonly used for the same person. A
+// name may appear in several groups, like Al for Alan and Albert.
+var nicknameGroups = [][]string{
+	{"alan", "allan", "allen", "al"},
+	{"albert", "al", "bert", "bertie"},
+	{"alexander", "alex", "alec", "al", "sandy", "xander"},
+	{"alexandra", "alex", "lexi", "sandra", "sandy"},
+	{"alfred", "al", "alf", "fred", "freddie"},
+	{"andrew", "andy", "drew"},
+	{"anthony", "tony", "ant"},
+	{"benjamin", "ben", "benny", "benji"},
+	{"catherine", "katherine", "kathryn", "cathy", "kathy", "kate", "katie", "cat", "kat"},
+	{"charles", "charlie", "chuck", "chaz"},
+	{"christina", "christine", "chris", "chrissy", "tina"},
+	{"christopher", "chris", "kit", "topher"},
+	{"daniel", "dan", "danny"},
+	{"david", "dave", "davey"},
+	{"deborah", "debra", "deb", "debbie"},
+	{"edward", "ed", "eddie", "ted", "ned"},
+	{"elizabeth", "liz", "lizzie", "beth", "betty", "eliza", "libby"},
+	{"frederick", "fred", "freddie", "rick"},
+	{"gregory", "greg"},
+	{"james", "jim", "jimmy", "jamie"},
+	{"jennifer", "jen", "jenny"},
+	{"jessica", "jess", "jessie"},
+	{"jonathan", "john", "jon", "johnny", "jonny"},
+	{"john", "jack", "johnny"},
+	{"joseph", "joe", "joey"},
+	{"joshua", "josh"},
+	{"margaret", "maggie", "meg", "peggy", "greta"},
+	{"matthew", "matt", "matty"},
+	{"michael", "mike", "mikey", "mick", "mickey"},
+	{"nathan", "nathaniel", "nate", "nat"},
+	{"nicholas", "nick", "nicky"},
+	{"patricia", "pat", "patty", "trish"},
+	{"patrick", "pat", "paddy"},
+	{"peter", "pete"},
+	{"rebecca", "becky", "becca"},
+	{"richard", "rich", "rick", "ricky", "dick"},
+	{"robert", "rob", "robbie", "bob", "bobby", "bert"},
+	{"samantha", "sam", "sammy"},
+	{"samuel", "sam", "sammy"},
+	{"stephen", "steven", "steve", "stevie"},
+	{"susan", "sue", "susie"},
+	{"theodore", "theo", "ted", "teddy"},
+	{"thomas", "tom", "tommy"},
+	{"timothy", "tim", "timmy"},
+	{"victoria", "vicky", "tori"},
+	{"william", "will", "bill", "billy", "liam", "willy"},
+	{"zachary", "zach", "zack"},
+}
+
+// nicknames maps each name to the groups it appears in
+var nicknames = func() map[string][]int {
+	index := make(map[string][]int)
+	for group, names := range nicknameGroups {
+		for _, name := range names {
+			index[name] = append(index[name], group)
+		}
+	}
+	return index
+}()
+
+// rankNames scores each candidate against the name being entered and returns
+// the ones close enough to suggest, best first
+func rankNames(name string, candidates []string) []types.NameMatch {
+	matches := []types.NameMatch{}
+	for _, candidate := range candidates {
+		score, reason := scoreName(name, candidate)
+		if score >= minNameMatchScore {
+			matches = append(matches, types.NameMatch{Name: candidate, Score: score, Reason: reason})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Name < matches[j].Name
+	})
+	return matches
+}
+
+// scoreName rates how likely two names belong to the same person, from 0 to
+// 1, and says why. Spelling similarity is the average of Jaro-Winkler, which
+// favours a shared start, and edit distance, which keeps short names like Al
+// from matching every longer name that begins the same way. Names that sound
+// alike get halfway closer to 1.
+func scoreName(a, b string) (float64, string) {
+	a, b = normalizeName(a), normalizeName(b)
+	if a == "" || b == "" {
+		return 0, types.NameMatchSpelling
+	}
+	if a == b {
+		return 1, types.NameMatchSame
+	}
+
+	score := (jaroWinkler(a, b) + levenshteinSimilarity(a, b)) / 2
+	if areNicknames(a, b) && score < nicknameScore {
+		return nicknameScore, types.NameMatchNickname
+	}
+	if soundsAlike(a, b) {
+		return score + (1-score)/2, types.NameMatchSound
+	}
+	return score, types.NameMatchSpelling
+}
+
+// normalizeName lowercases a name, strips accents and punctuation, and
+// collapses whitespace, so "  José-Luis " becomes "jose luis"
+func normalizeName(name string) string {
+	stripAccents := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	if stripped, _, err := transform.String(stripAccents, name); err == nil {
+		name = stripped
+	}
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, name)
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// areNicknames reports whether two normalised names share a nickname group
+// in their first word, with any remaining words the same
+func areNicknames(a, b string) bool {
+	firstA, restA, _ := strings.Cut(a, " ")
+	firstB, restB, _ := strings.Cut(b, " ")
+	if restA != restB {
+		return false
+	}
+	for _, group := range nicknames[firstA] {
+		for _, other := range nicknames[firstB] {
+			if group == other {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// soundsAlike compares two normalised names word by word by their phonetic
+// keys. Keys shorter than three sounds are ignored, as too many short names
+// share them, like Tom and Tim.
+func soundsAlike(a, b string) bool {
+	wordsA, wordsB := strings.Fields(a), strings.Fields(b)
+	if len(wordsA) != len(wordsB) {
+		return false
+	}
+	for i := range wordsA {
+		keyA := phoneticKey(wordsA[i])
+		if len(keyA) < 3 || keyA != phoneticKey(wordsB[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// phoneticSpellings rewrites letter groups to the sound they usually make,
+// longest first
+var phoneticSpellings = strings.NewReplacer(
+	"sch", "sk", "tch", "x", "chr", "kr",
+	"ph", "f", "ck", "k", "ch", "x", "sh", "x", "th", "t", "gh", "g",
+	"dg", "j", "qu", "kw", "q", "k", "x", "ks", "z", "s", "v", "f",
+)
+
+// phoneticKey reduces a word to a rough key of how it sounds, in the spirit
+// of Soundex and Metaphone: the first letter followed by the consonant
+// sounds, so John and Jon, or Katherine and Kathryn, share a key
+func phoneticKey(word string) string {
+	for _, prefix := range []string{"kn", "wr", "ps", "gn"} {
+		if strings.HasPrefix(word, prefix) {
+			word = word[1:]
+			break
+		}
+	}
+	letters := []rune(phoneticSpellings.Replace(word))
+
+	var key []rune
+	for i, r := range letters {
+		softened := i+1 < len(letters) && strings.ContainsRune("eiy", letters[i+1])
+		switch {
+		case r == 'c' && softened:
+			r = 's'
+		case r == 'c':
+			r = 'k'
+		case r == 'g' && softened:
+			r = 'j'
+		case i > 0 && strings.ContainsRune("aeiouyhw", r):
+			continue
+		}
+		if len(key) > 0 && key[len(key)-1] == r {
+			continue
+		}
+		key = append(key, r)
+	}
+	return string(key)
+}
+
+// jaroWinkler scores two strings from 0 to 1 by the characters they share
+// near the same position, boosted by a common prefix of up to four
+func jaroWinkler(a, b string) float64 {
+	s1, s2 := []rune(a), []rune(b)
+	if len(s1) == 0 || len(s2) == 0 {
+		return 0
+	}
+
+	window := max(max(len(s1), len(s2))/2-1, 0)
+	matched1 := make([]bool, len(s1))
+	matched2 := make([]bool, len(s2))
+	matches := 0
+	for i := range s1 {
+		for j := max(0, i-window); j < min(len(s2), i+window+1); j++ {
+			if matched2[j] || s1[i] != s2[j] {
+				continue
+			}
+			matched1[i], matched2[j] = true, true
+			matches++
+			break
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	// Matched characters that appear in a different order
+	transpositions, j := 0, 0
+	for i := range s1 {
+		if !matched1[i] {
+			continue
+		}
+		for !matched2[j] {
+			j++
+		}
+		if s1[i] != s2[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(s1)) + m/float64(len(s2)) + (m-float64(transpositions/2))/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(s1), len(s2)) && s1[prefix] == s2[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+// levenshteinSimilarity turns the edit distance between two strings into a
+// score from 0 to 1 relative to the longer one
+func levenshteinSimilarity(a, b string) float64 {
+	s1, s2 := []rune(a), []rune(b)
+	longest := max(len(s1), len(s2))
+	if longest == 0 {
+		return 1
+	}
+
+	previous := make([]int, len(s2)+1)
+	current := make([]int, len(s2)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(s1); i++ {
+		current[0] = i
+		for j := 1; j <= len(s2); j++ {
+			cost := 1
+			if s1[i-1] == s2[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return 1 - float64(previous[len(s2)])/float64(longest)
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/thornzero/movie-poll/types"
+)
+
+// Reference values from the Jaro-Winkler literature
+func TestJaroWinkler(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"MARTHA", "MARHTA", 0.961},
+		{"DWAYNE", "DUANE", 0.840},
+		{"DIXON", "DICKSONX", 0.813},
+		{"JELLYFISH", "SMELLYFISH", 0.896},
+		{"MARTHA", "MARTHA", 1},
+		{"ABC", "XYZ", 0},
+		{"", "MARTHA", 0},
+	}
+	for _, tt := range tests {
+		if got := jaroWinkler(tt.a, tt.b); math.Abs(got-tt.want) > 0.001 {
+			t.Errorf("jaroWinkler(%q, %q) = %.4f, want %.3f", tt.a, tt.b, got, tt.want)
+		}
+		if got := jaroWinkler(tt.b, tt.a); math.Abs(got-tt.want) > 0.001 {
+			t.Errorf("jaroWinkler(%q, %q) = %.4f, want %.3f", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestLevenshteinSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"kitten", "sitting", 1 - 3.0/7},
+		{"flaw", "lawn", 0.5},
+		{"josé", "jose", 0.75},
+		{"same", "same", 1},
+		{"", "", 1},
+	}
+	for _, tt := range tests {
+		if got := levenshteinSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("levenshteinSimilarity(%q, %q) = %.4f, want %.4f", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	tests := map[string]string{
+		"  José-Luis ":   "jose luis",
+		"Zoë\tO'Brien":   "zoe o brien",
+		"ÅSA":            "asa",
+		"Mary   Ann":     "mary ann",
+		"   ":            "",
+		"Renée Fontaine": "renee fontaine",
+	}
+	for name, want := range tests {
+		if got := normalizeName(name); got != want {
+			t.Errorf("normalizeName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestScoreName(t *testing.T) {
+	tests := []struct {
+		a, b   string
+		match  bool
+		reason string
+	}{
+		{"Jonathan", "Johnathan", true, types.NameMatchSound},
+		{"Katherine", "Kathryn", true, types.NameMatchNickname},
+		{"Al", "Alan", true, types.NameMatchNickname},
+		{"Al", "Albert", true, types.NameMatchNickname},
+		{"Al", "Alice", false, types.NameMatchSpelling},
+		{"Al", "Ali", false, types.NameMatchSpelling},
+		{"Al", "Sal", false, types.NameMatchSpelling},
+		{"Tom", "Tim", false, types.NameMatchSpelling},
+		{"José", " jose  ", true, types.NameMatchSame},
+		{"José-Luis", "jose luis", true, types.NameMatchSame},
+		{"Jon Smith", "John Smith", true, types.NameMatchSpelling},
+		{"Jim Smith", "James Smith", true, types.NameMatchNickname},
+		{"Jim Smith", "James Jones", false, types.NameMatchSpelling},
+		{"", "Alan", false, types.NameMatchSpelling},
+	}
+	for _, tt := range tests {
+		score, reason := scoreName(tt.a, tt.b)
+		if match := score >= minNameMatchScore; match != tt.match {
+			t.Errorf("scoreName(%q, %q) = %.3f, match %v, want %v", tt.a, tt.b, score, match, tt.match)
+		}
+		if reason != tt.reason {
+			t.Errorf("scoreName(%q, %q) reason %q, want %q", tt.a, tt.b, reason, tt.reason)
+		}
+	}
+}
+
+func TestRankNames(t *testing.T) {
+	candidates := []string{"Sal", "Alice", "Albert", "Hal", "Ali", "Alan", "Allison", "Al", "Alex"}
+	var got []string
+	for _, match := range rankNames("Al", candidates) {
+		got = append(got, match.Name)
+	}
+	want := []string{"Al", "Alan", "Albert", "Alex"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rankNames(Al) = %v, want %v", got, want)
+	}
+
+	if matches := rankNames("Zed", candidates); len(matches) != 0 {
+		t.Errorf("rankNames(Zed) = %v, want none", matches)
+	}
+}
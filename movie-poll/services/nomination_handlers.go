@@ -0,0 +1,204 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/thornzero/movie-poll/models"
+	"github.com/thornzero/movie-poll/views"
+	"gorm.io/gorm"
+)
+
+// handleNominate handles the page where participants suggest movies
+func (hr *HandlerRegistry) handleNominate(w http.ResponseWriter, r *http.Request) {
+	sessionData := Session.GetSessionData(r)
+	if sessionData.UserName == "" {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	nominations, err := DB.GetUserNominations(sessionData.UserName, sessionData.VoterDeviceID())
+	if err != nil {
+		LogErrorf("Error getting nominations for %s: %v", sessionData.UserName, err)
+		http.Error(w, "Failed to load nominations", http.StatusInternalServerError)
+		return
+	}
+
+	nominateData := views.NominateData{
+		UserName:    sessionData.UserName,
+		Nominations: convertNominationsToInfo(nominations),
+	}
+
+	views.NominatePage(nominateData).Render(r.Context(), w)
+}
+
+// handleNominationSearch handles TMDB searches from the nomination page
+func (hr *HandlerRegistry) handleNominationSearch(w http.ResponseWriter, r *http.Request) {
+	sessionData := Session.GetSessionData(r)
+	if sessionData.UserName == "" {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		views.NominationSearchResults(nil).Render(r.Context(), w)
+		return
+	}
+
+	// Check if TMDB API key is configured
+	if Config.TMDBAPIKey == "" {
+		LogErrorf("TMDB API key not configured")
+		http.Error(w, "Movie search is not available right now", http.StatusServiceUnavailable)
+		return
+	}
+
+	searchResult, err := TMDB.SearchMovies(MovieID{Title: query}, 1)
+	if err != nil {
+		LogErrorf("Error searching movies: %v", err)
+		http.Error(w, "Failed to search movies", http.StatusInternalServerError)
+		return
+	}
+
+	views.NominationSearchResults(searchResult.Results).Render(r.Context(), w)
+}
+
+// handleSubmitNomination handles a participant nominating a TMDB movie
+func (hr *HandlerRegistry) handleSubmitNomination(w http.ResponseWriter, r *http.Request) {
+	sessionData := Session.GetSessionData(r)
+	if sessionData.UserName == "" {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	tmdbID, err := strconv.Atoi(r.FormValue("tmdb_id"))
+	if err != nil || tmdbID <= 0 {
+		http.Error(w, "Invalid TMDB ID", http.StatusBadRequest)
+		return
+	}
+	title := strings.TrimSpace(r.FormValue("title"))
+	if title == "" {
+		http.Error(w, "Title is required", http.StatusBadRequest)
+		return
+	}
+
+	nomination := models.Nomination{
+		TMDBID:     tmdbID,
+		Title:      title,
+		PosterPath: r.FormValue("poster_path"),
+		Pitch:      strings.TrimSpace(r.FormValue("pitch")),
+		UserName:   sessionData.UserName,
+		DeviceID:   sessionData.VoterDeviceID(),
+	}
+	if year, err := strconv.Atoi(r.FormValue("year")); err == nil && year > 0 {
+		nomination.Year = &year
+	}
+
+	_, err = DB.Nominate(nomination)
+	switch {
+	case errors.Is(err, ErrPitchTooLong):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, ErrAlreadyNominated), errors.Is(err, ErrAlreadyInPoll):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		LogErrorf("Error saving nomination from %s: %v", sessionData.UserName, err)
+		http.Error(w, "Failed to save nomination", http.StatusInternalServerError)
+		return
+	}
+
+	if r.Header.Get("HX-Request") != "true" {
+		http.Redirect(w, r, "/nominate", http.StatusSeeOther)
+		return
+	}
+	views.NominationSubmitted(title).Render(r.Context(), w)
+}
+
+// handleAdminApproveNomination handles adding a nominated movie to the poll
+func (hr *HandlerRegistry) handleAdminApproveNomination(w http.ResponseWriter, r *http.Request) {
+	hr.reviewNomination(w, r, func(id uint, reviewer string) error {
+		_, err := DB.ApproveNomination(id, reviewer)
+		return err
+	})
+}
+
+// handleAdminRejectNomination handles turning down a nomination
+func (hr *HandlerRegistry) handleAdminRejectNomination(w http.ResponseWriter, r *http.Request) {
+	hr.reviewNomination(w, r, DB.RejectNomination)
+}
+
+// reviewNomination applies an approve/reject action to the nomination in the URL
+func (hr *HandlerRegistry) reviewNomination(w http.ResponseWriter, r *http.Request, action func(id uint, reviewer string) error) {
+	// Check if user is logged in as admin
+	sessionData := Session.GetSessionData(r)
+	if sessionData.AdminUser == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	nominationID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || nominationID <= 0 {
+		http.Error(w, "Invalid nomination ID", http.StatusBadRequest)
+		return
+	}
+
+	err = action(uint(nominationID), sessionData.AdminUser.Username)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		http.Error(w, "Nomination not found", http.StatusNotFound)
+		return
+	case errors.Is(err, ErrNominationClosed):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		LogErrorf("Error reviewing nomination %d: %v", nominationID, err)
+		http.Error(w, "Failed to review nomination", http.StatusInternalServerError)
+		return
+	}
+
+	if r.Header.Get("HX-Request") != "true" {
+		http.Redirect(w, r, "/admin/movies", http.StatusSeeOther)
+		return
+	}
+
+	nominations, err := DB.GetNominations(models.NominationPending)
+	if err != nil {
+		LogErrorf("Error getting nominations: %v", err)
+		http.Error(w, "Failed to load nominations", http.StatusInternalServerError)
+		return
+	}
+	views.NominationQueue(convertNominationsToInfo(nominations)).Render(r.Context(), w)
+}
+
+// convertNominationsToInfo converts nominations to their display form
+func convertNominationsToInfo(nominations []models.Nomination) []views.NominationInfo {
+	infos := make([]views.NominationInfo, len(nominations))
+	for i, nomination := range nominations {
+		year := 0
+		if nomination.Year != nil {
+			year = *nomination.Year
+		}
+		infos[i] = views.NominationInfo{
+			ID:         int(nomination.ID),
+			TMDBID:     nomination.TMDBID,
+			Title:      nomination.Title,
+			Year:       year,
+			PosterPath: nomination.PosterPath,
+			Pitch:      nomination.Pitch,
+			UserName:   nomination.UserName,
+			Status:     nomination.Status,
+			CreatedAt:  nomination.CreatedAt,
+		}
+	}
+	return infos
+}
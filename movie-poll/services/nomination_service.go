@@ -0,0 +1,137 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/thornzero/movie-poll/models"
+	"gorm.io/gorm"
+)
+
+// maxPitchLength keeps nomination pitches to a sentence or two
+const maxPitchLength = 280
+
+var (
+	ErrAlreadyNominated = errors.New("movie has already been nominated")
+	ErrAlreadyInPoll    = errors.New("movie is already in the poll")
+	ErrPitchTooLong     = errors.New("pitch is too long")
+	ErrNominationClosed = errors.New("nomination has already been reviewed")
+)
+
+type NominationService struct {
+	db *gorm.DB
+}
+
+func NewNominationService(db *gorm.DB) *NominationService {
+	return &NominationService{db: db}
+}
+
+// Nominate queues a TMDB movie for admin review
+func (s *NominationService) Nominate(nomination models.Nomination) (*models.Nomination, error) {
+	if len([]rune(nomination.Pitch)) > maxPitchLength {
+		return nil, ErrPitchTooLong
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.Movie{}).Where("tmdb_id = ?", nomination.TMDBID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrAlreadyInPoll
+		}
+
+		err := tx.Model(&models.Nomination{}).
+			Where("tmdb_id = ? AND status = ?", nomination.TMDBID, models.NominationPending).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrAlreadyNominated
+		}
+
+		nomination.ID = 0
+		nomination.Status = models.NominationPending
+		nomination.MovieID = nil
+		return tx.Create(&nomination).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &nomination, nil
+}
+
+// GetNominations returns nominations with the given status, oldest first.
+// An empty status returns every nomination.
+func (s *NominationService) GetNominations(status string) ([]models.Nomination, error) {
+	var nominations []models.Nomination
+	query := s.db.Order("created_at, id")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Find(&nominations).Error
+	return nominations, err
+}
+
+// GetUserNominations returns a participant's nominations, newest first
+func (s *NominationService) GetUserNominations(userName, deviceID string) ([]models.Nomination, error) {
+	var nominations []models.Nomination
+	err := s.db.Where("user_name = ? AND device_id = ?", userName, deviceID).
+		Order("created_at DESC, id DESC").
+		Find(&nominations).Error
+	return nominations, err
+}
+
+// GetPendingNomination returns a nomination that is still awaiting review
+func (s *NominationService) GetPendingNomination(id uint) (*models.Nomination, error) {
+	var nomination models.Nomination
+	if err := s.db.First(&nomination, id).Error; err != nil {
+		return nil, err
+	}
+	if nomination.Status != models.NominationPending {
+		return nil, ErrNominationClosed
+	}
+	return &nomination, nil
+}
+
+// Review closes a pending nomination. Approved nominations record the movie
+// they were added as.
+func (s *NominationService) Review(id uint, status string, movieID *uint, reviewer string) error {
+	now := time.Now()
+	result := s.db.Model(&models.Nomination{}).
+		Where("id = ? AND status = ?", id, models.NominationPending).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"movie_id":    movieID,
+			"reviewed_by": reviewer,
+			"reviewed_at": now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNominationClosed
+	}
+	return nil
+}
+
+// GetMovieNominations returns the approved nomination behind each movie
+// that came from the queue
+func (s *NominationService) GetMovieNominations() (map[uint]models.Nomination, error) {
+	var nominations []models.Nomination
+	err := s.db.Where("status = ? AND movie_id IS NOT NULL", models.NominationApproved).
+		Order("reviewed_at").
+		Find(&nominations).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byMovie := make(map[uint]models.Nomination, len(nominations))
+	for _, nomination := range nominations {
+		if _, ok := byMovie[*nomination.MovieID]; !ok {
+			byMovie[*nomination.MovieID] = nomination
+		}
+	}
+	return byMovie, nil
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/thornzero/movie-poll/types"
+	"github.com/thornzero/movie-poll/views"
+)
+
+// handleLinkDevice shows the page for moving a voter to another device,
+// with ?code= filled in when it was opened from a pairing QR code
+func (hr *HandlerRegistry) handleLinkDevice(w http.ResponseWriter, r *http.Request) {
+	sessionData := Session.GetSessionData(r)
+	views.LinkDevicePage(views.LinkDeviceData{
+		UserName: sessionData.UserName,
+		Code:     r.URL.Query().Get("code"),
+	}).Render(r.Context(), w)
+}
+
+// handleCreatePairingCode makes a pairing code for the voter on this device
+func (hr *HandlerRegistry) handleCreatePairingCode(w http.ResponseWriter, r *http.Request) {
+	sessionData := Session.GetSessionData(r)
+	if sessionData.UserName == "" {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	pairing, err := DB.CreatePairingCode(types.Identity{UserName: sessionData.UserName, DeviceID: sessionData.VoterDeviceID()})
+	if err != nil {
+		LogErrorf("Error creating pairing code for %s: %v", sessionData.UserName, err)
+		http.Error(w, "Failed to create pairing code", http.StatusInternalServerError)
+		return
+	}
+
+	views.PairingCode(views.PairingCodeData{
+		Code:      pairing.Code,
+		ExpiresAt: pairing.ExpiresAt,
+		LinkURL:   joinURL(r) + "link-device?" + url.Values{"code": {pairing.Code}}.Encode(),
+	}).Render(r.Context(), w)
+}
+
+// handleClaimPairingCode links this device to the voter who made the code,
+// then sends it back to voting with their votes loaded
+func (hr *HandlerRegistry) handleClaimPairingCode(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+	code := strings.TrimSpace(r.FormValue("code"))
+	if code == "" {
+		http.Error(w, "Code is required", http.StatusBadRequest)
+		return
+	}
+
+	sessionData := Session.GetSessionData(r)
+	claimant := types.Identity{UserName: sessionData.UserName, DeviceID: sessionData.VoterDeviceID()}
+	pairing, err := DB.ClaimPairingCode(code, sessionData.DeviceID, claimant)
+	switch {
+	case errors.Is(err, ErrInvalidPairingCode), errors.Is(err, ErrPairingSameDevice):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		LogErrorf("Error claiming pairing code on device %s: %v", sessionData.DeviceID, err)
+		http.Error(w, "Failed to link device", http.StatusInternalServerError)
+		return
+	}
+	LogInfof("Device %s linked to %s on device %s", sessionData.DeviceID, pairing.UserName, pairing.DeviceID)
+	if err := DB.AddDeviceName(sessionData.DeviceID, pairing.UserName); err != nil {
+		LogErrorf("Error recording name on device %s: %v", sessionData.DeviceID, err)
+	}
+
+	// A new identity deserves a new session token
+	if err := Session.RenewToken(r.Context()); err != nil {
+		LogErrorf("Error renewing session token: %v", err)
+	}
+	sessionData.UserName = pairing.UserName
+	sessionData.LinkedDeviceID = pairing.DeviceID
+	Session.PutSessionData(r, sessionData)
+	Session.ReloadSessionVotes(r, sessionData)
+
+	if r.Header.Get("HX-Request") != "true" {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+	w.Header().Set("HX-Redirect", "/")
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/thornzero/movie-poll/models"
+	"github.com/thornzero/movie-poll/types"
+	"gorm.io/gorm"
+)
+
+const (
+	pairingCodeDigits   = 6
+	pairingCodeLifetime = 5 * time.Minute
+
+	// pairingMergeAuthor stands in for the admin on merges made by a device
+	// claiming a pairing code
+	pairingMergeAuthor = "device pairing"
+)
+
+var (
+	ErrInvalidPairingCode = errors.New("pairing code is wrong or has expired")
+	ErrPairingSameDevice  = errors.New("pairing code was made on this device")
+)
+
+type PairingService struct {
+	db *gorm.DB
+}
+
+func NewPairingService(db *gorm.DB) *PairingService {
+	return &PairingService{db: db}
+}
+
+// CreateCode makes a new pairing code for a voter, replacing any code they
+// are still showing. Expired codes nobody claimed are cleared out on
+// the way.
+func (s *PairingService) CreateCode(owner types.Identity) (*models.DevicePairing, error) {
+	var pairing models.DevicePairing
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Where("claimed_at IS NULL AND (expires_at < ? OR device_id = ?)", now, owner.DeviceID).
+			Delete(&models.DevicePairing{}).Error
+		if err != nil {
+			return err
+		}
+
+		code, err := unusedPairingCode(tx, now)
+		if err != nil {
+			return err
+		}
+		pairing = models.DevicePairing{
+			Code:      code,
+			DeviceID:  owner.DeviceID,
+			UserName:  owner.UserName,
+			ExpiresAt: now.Add(pairingCodeLifetime),
+		}
+		return tx.Create(&pairing).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pairing, nil
+}
+
+// ClaimCode links the claiming device to the one that made the code, so it
+// votes as the same voter from then on while keeping its own device ID.
+// claimant is who the device votes as so far. Anything they cast under a
+// name of their own joins the owner through an identity merge, keeping the
+// newer vote, ballot or RSVP where both cast one, so nothing cast on either
+// device is lost.
+func (s *PairingService) ClaimCode(code, deviceID string, claimant types.Identity) (*models.DevicePairing, error) {
+	var pairing models.DevicePairing
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Where("code = ? AND claimed_at IS NULL AND expires_at >= ?", code, now).
+			First(&pairing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidPairingCode
+		}
+		if err != nil {
+			return err
+		}
+		if pairing.DeviceID == claimant.DeviceID {
+			return ErrPairingSameDevice
+		}
+
+		if claimant.UserName != "" {
+			owner := types.Identity{UserName: pairing.UserName, DeviceID: pairing.DeviceID}
+			merge, err := mergePairedIdentity(tx, claimant, owner)
+			if err != nil {
+				return err
+			}
+			if merge != nil {
+				pairing.MergeID = &merge.ID
+			}
+		}
+
+		// Link the device the claimant votes under, which is this one unless
+		// it was linked before, taking along every device sharing its votes
+		if err := NewDeviceService(tx).LinkDevice(claimant.DeviceID, pairing.DeviceID); err != nil {
+			return err
+		}
+
+		pairing.ClaimedBy = deviceID
+		pairing.ClaimedAt = &now
+		return tx.Save(&pairing).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pairing, nil
+}
+
+// mergePairedIdentity folds everything the claimant cast into the owner's
+// identity, returning nil if they haven't cast anything
+func mergePairedIdentity(tx *gorm.DB, claimant, owner types.Identity) (*models.IdentityMerge, error) {
+	identities := []types.Identity{claimant, owner}
+	hasRecords, err := identityHasRecords(tx, claimant)
+	if err != nil || !hasRecords {
+		return nil, err
+	}
+
+	// With nothing in Keep, each clash keeps the newer vote
+	return NewMergeService(tx).MergeIdentities(types.MergeRequest{
+		Identities:    identities,
+		CanonicalName: owner.UserName,
+		DeviceID:      owner.DeviceID,
+		AdminUser:     pairingMergeAuthor,
+	})
+}
+
+// unusedPairingCode picks a random code that no live pairing is using
+func unusedPairingCode(tx *gorm.DB, now time.Time) (string, error) {
+	limit := big.NewInt(1)
+	for range pairingCodeDigits {
+		limit.Mul(limit, big.NewInt(10))
+	}
+
+	for range 10 {
+		n, err := rand.Int(rand.Reader, limit)
+		if err != nil {
+			return "", err
+		}
+		code := fmt.Sprintf("%0*d", pairingCodeDigits, n)
+
+		var taken int64
+		err = tx.Model(&models.DevicePairing{}).
+			Where("code = ? AND claimed_at IS NULL AND expires_at >= ?", code, now).
+			Count(&taken).Error
+		if err != nil {
+			return "", err
+		}
+		if taken == 0 {
+			return code, nil
+		}
+	}
+	return "", errors.New("no free pairing code")
+}
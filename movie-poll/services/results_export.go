@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/thornzero/movie-poll/models"
+	"github.com/thornzero/movie-poll/types"
+	"github.com/thornzero/movie-poll/views"
+)
+
+var ErrInvalidExportFormat = errors.New("export format must be csv, json or html")
+
+// Results export formats
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+	ExportFormatHTML = "html"
+)
+
+// ExportOptions controls what a results export includes
+type ExportOptions struct {
+	IncludeVotes bool // add the voter × movie vibe matrix
+	Anonymize    bool // replace voter names in the matrix; always on with ANONYMIZE_VOTERS
+}
+
+// GetResultsExport gathers the ranked results, stats and optionally the vote
+// matrix for an export
+func (g *GORMService) GetResultsExport(options types.ResultsOptions, exportOptions ExportOptions) (*types.ResultsExport, error) {
+	results, err := g.GetResultsSummary(options)
+	if err != nil {
+		return nil, err
+	}
+	if results == nil {
+		results = []types.VotingSummary{}
+	}
+
+	stats, err := g.GetVotingStats(options.RoundID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &types.ResultsExport{
+		GeneratedAt: time.Now().Unix(),
+		Strategy:    g.GetActiveAppealStrategy().Label(),
+		Stats:       *stats,
+		Results:     results,
+	}
+	if options.RoundID > 0 {
+		round, err := g.roundService.GetRound(options.RoundID)
+		if err != nil {
+			return nil, err
+		}
+		export.RoundName = round.Name
+	}
+
+	var going map[[2]string]bool
+	if options.EventID > 0 {
+		event, err := g.eventService.GetEvent(options.EventID)
+		if err != nil {
+			return nil, err
+		}
+		export.EventName = event.Name
+
+		attendees, err := g.eventService.GetGoingAttendees(options.EventID)
+		if err != nil {
+			return nil, err
+		}
+		going = make(map[[2]string]bool, len(attendees))
+		for _, rsvp := range attendees {
+			going[[2]string{rsvp.UserName, rsvp.DeviceID}] = true
+		}
+	}
+
+	if exportOptions.IncludeVotes {
+		anonymize := exportOptions.Anonymize || (Config != nil && Config.AnonymizeVoters)
+		matrix, err := g.buildVoteMatrix(options.RoundID, results, going, anonymize)
+		if err != nil {
+			return nil, err
+		}
+		export.Votes = matrix
+	}
+	return export, nil
+}
+
+// buildVoteMatrix lays out the round's votes on the ranked movies, one row
+// per voter. With going set, only those attendees' votes are included.
+func (g *GORMService) buildVoteMatrix(roundID uint, results []types.VotingSummary, going map[[2]string]bool, anonymize bool) (*types.VoteMatrix, error) {
+	matrix := &types.VoteMatrix{
+		Movies: make([]string, len(results)),
+		Voters: []string{},
+		Vibes:  [][]int{},
+	}
+	columns := make(map[uint]int, len(results))
+	for i, result := range results {
+		matrix.Movies[i] = result.Title
+		columns[uint(result.MovieID)] = i
+	}
+
+	var votes []models.Vote
+	err := g.db.Scopes(scopeRound(roundID)).Order("user_name, device_id").Find(&votes).Error
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make(map[[2]string]int)
+	for _, vote := range votes {
+		column, ok := columns[vote.MovieID]
+		if !ok {
+			continue
+		}
+		voter := [2]string{vote.UserName, vote.DeviceID}
+		if going != nil && !going[voter] {
+			continue
+		}
+
+		row, ok := rows[voter]
+		if !ok {
+			row = len(matrix.Voters)
+			rows[voter] = row
+			name := vote.UserName
+			if anonymize {
+				name = "Voter " + strconv.Itoa(row+1)
+			}
+			matrix.Voters = append(matrix.Voters, name)
+			matrix.Vibes = append(matrix.Vibes, make([]int, len(results)))
+		}
+		matrix.Vibes[row][column] = vote.Vibe
+	}
+	return matrix, nil
+}
+
+// WriteResultsExport writes an export in the given format
+func WriteResultsExport(w io.Writer, export *types.ResultsExport, format string) error {
+	switch format {
+	case ExportFormatCSV:
+		return writeResultsCSV(w, export)
+	case ExportFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(export)
+	case ExportFormatHTML:
+		return views.ResultsExportPage(*export).Render(context.Background(), w)
+	default:
+		return ErrInvalidExportFormat
+	}
+}
+
+// writeResultsCSV writes the results, stats and vote matrix as CSV sections
+// separated by blank lines
+func writeResultsCSV(w io.Writer, export *types.ResultsExport) error {
+	writer := csv.NewWriter(w)
+
+	writer.Write([]string{"Rank", "Title", "Year", "Appeal Score", "Votes", "Seen", "Not Seen", "Average Vibe", "Median Vibe", "Vetoes", "Last Screened"})
+	for i, result := range export.Results {
+		year := ""
+		if result.Year != nil {
+			year = strconv.Itoa(*result.Year)
+		}
+		screened := ""
+		if result.ScreenedAt > 0 {
+			screened = time.Unix(result.ScreenedAt, 0).Format("2006-01-02")
+		}
+		writer.Write([]string{
+			strconv.Itoa(i + 1),
+			result.Title,
+			year,
+			strconv.FormatFloat(result.AppealScore, 'f', 2, 64),
+			strconv.Itoa(result.VoteCount),
+			strconv.Itoa(result.SeenCount),
+			strconv.Itoa(result.NotSeenCount),
+			strconv.FormatFloat(result.AverageVibe, 'f', 2, 64),
+			strconv.FormatFloat(result.MedianVibe, 'f', 1, 64),
+			strconv.Itoa(result.VetoCount),
+			screened,
+		})
+	}
+
+	writer.Write(nil)
+	writer.Write([]string{"Stat", "Value"})
+	writer.Write([]string{"Total Movies", strconv.Itoa(export.Stats.TotalMovies)})
+	writer.Write([]string{"Total Votes", strconv.Itoa(export.Stats.TotalVotes)})
+	writer.Write([]string{"Unique Voters", strconv.Itoa(export.Stats.UniqueVoters)})
+	writer.Write([]string{"Movies With Votes", strconv.Itoa(export.Stats.MoviesWithVotes)})
+	writer.Write([]string{"Average Appeal Score", strconv.FormatFloat(export.Stats.AverageAppealScore, 'f', 2, 64)})
+	writer.Write([]string{"Most Voted Movie", export.Stats.MostVotedMovie})
+	writer.Write([]string{"Appeal Strategy", export.Strategy})
+
+	if export.Votes != nil {
+		writer.Write(nil)
+		writer.Write(append([]string{"Voter"}, export.Votes.Movies...))
+		for i, voter := range export.Votes.Voters {
+			row := []string{voter}
+			for _, vibe := range export.Votes.Vibes[i] {
+				cell := ""
+				if vibe > 0 {
+					cell = strconv.Itoa(vibe)
+				}
+				row = append(row, cell)
+			}
+			writer.Write(row)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,301 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/thornzero/movie-poll/types"
+	"github.com/thornzero/movie-poll/views"
+)
+
+const (
+	defaultResultsTop      = 10
+	defaultResultsPageSize = 20
+	maxResultsPageSize     = 100
+)
+
+// handleResultsSummary returns the voting stats and the top movies as JSON.
+// It honours the same ?round= and ?event= parameters as the results page,
+// plus ?limit= for the number of movies.
+func (hr *HandlerRegistry) handleResultsSummary(w http.ResponseWriter, r *http.Request) {
+	options, ok := resolveResultsOptions(w, r)
+	if !ok {
+		return
+	}
+
+	limit := defaultResultsTop
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	summary, err := DB.GetResultsSummary(options)
+	if err != nil {
+		LogErrorf("Error fetching results summary: %v", err)
+		http.Error(w, "Failed to load results", http.StatusInternalServerError)
+		return
+	}
+	if len(summary) > limit {
+		summary = summary[:limit]
+	}
+	if summary == nil {
+		summary = []types.VotingSummary{}
+	}
+
+	stats, err := DB.GetVotingStats(options.RoundID)
+	if err != nil {
+		LogErrorf("Error fetching voting stats: %v", err)
+		http.Error(w, "Failed to load results", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"stats":    stats,
+		"summary":  summary,
+		"strategy": DB.GetActiveAppealStrategy().Name(),
+	})
+}
+
+// handleResultsList returns a page of results as JSON. Besides ?round= and
+// ?event= it accepts:
+//
+//	sort              appeal (default), votes, vibe, visibility or divisive
+//	order             asc or desc; defaults to best first
+//	year              only movies released that year
+//	min_seen_ratio    share of voters who have seen it, 0-1
+//	max_seen_ratio
+//	min_participation minimum number of votes
+//	min_polarization  how divisive it is, 0-1
+//	max_polarization
+//	page, page_size   1-based page, up to 100 per page
+func (hr *HandlerRegistry) handleResultsList(w http.ResponseWriter, r *http.Request) {
+	options, ok := resolveResultsOptions(w, r)
+	if !ok {
+		return
+	}
+
+	query, err := parseResultsQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	list, err := DB.GetResultsList(options, query)
+	if errors.Is(err, ErrInvalidResultsSort) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		LogErrorf("Error fetching results list: %v", err)
+		http.Error(w, "Failed to load results", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// handleResultsLive streams fresh stats, rankings and movers to the results
+// page as server-sent events whenever a vote lands. It takes the same
+// parameters as the page.
+func (hr *HandlerRegistry) handleResultsLive(w http.ResponseWriter, r *http.Request) {
+	options, ok := resolveResultsOptions(w, r)
+	if !ok {
+		return
+	}
+	query, err := parseResultsQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query.PageSize = 0
+
+	serveEventStream(w, r, func(stream *http.ResponseController) error {
+		return writeResultsUpdate(w, stream, r, options, query)
+	}, LiveResults)
+}
+
+// writeResultsUpdate renders the live parts of the results page and sends
+// each as its own event. Failing to load results skips the update but keeps
+// the stream open.
+func writeResultsUpdate(w http.ResponseWriter, stream *http.ResponseController, r *http.Request, options types.ResultsOptions, query types.ResultsQuery) error {
+	data, err := buildResultsData(r, options, query)
+	if err != nil {
+		LogErrorf("Error fetching live results: %v", err)
+		return nil
+	}
+
+	fragments := []struct {
+		event     string
+		component templ.Component
+	}{
+		{views.LiveEventStats, views.StatsCards(data.Stats)},
+		{views.LiveEventMovers, views.LiveMovers(data.Movers, data.Selection)},
+		{views.LiveEventResults, views.ResultsList(data.Movies, data.Selection)},
+	}
+	for _, fragment := range fragments {
+		var html bytes.Buffer
+		if err := fragment.component.Render(r.Context(), &html); err != nil {
+			return err
+		}
+		if err := writeLiveEvent(w, stream, fragment.event, html.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleAdminExportResults downloads the results as CSV or JSON, or shows a
+// printable HTML page. ?votes=true adds the vote matrix and ?anonymize=true
+// hides the voters' names in it.
+func (hr *HandlerRegistry) handleAdminExportResults(w http.ResponseWriter, r *http.Request) {
+	// Check if user is logged in as admin
+	sessionData := Session.GetSessionData(r)
+	if sessionData.AdminUser == nil {
+		http.Redirect(w, r, "/admin", http.StatusSeeOther)
+		return
+	}
+
+	options, ok := resolveResultsOptions(w, r)
+	if !ok {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = ExportFormatHTML
+	}
+	contentTypes := map[string]string{
+		ExportFormatCSV:  "text/csv; charset=utf-8",
+		ExportFormatJSON: "application/json",
+		ExportFormatHTML: "text/html; charset=utf-8",
+	}
+	contentType, ok := contentTypes[format]
+	if !ok {
+		http.Error(w, ErrInvalidExportFormat.Error(), http.StatusBadRequest)
+		return
+	}
+
+	export, err := DB.GetResultsExport(options, ExportOptions{
+		IncludeVotes: r.URL.Query().Get("votes") == "true",
+		Anonymize:    r.URL.Query().Get("anonymize") == "true",
+	})
+	if err != nil {
+		LogErrorf("Error building results export: %v", err)
+		http.Error(w, "Failed to export results", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if format != ExportFormatHTML {
+		filename := "results-" + time.Now().Format("2006-01-02") + "." + format
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	}
+	if err := WriteResultsExport(w, export, format); err != nil {
+		LogErrorf("Error writing results export: %v", err)
+	}
+}
+
+// resolveResultsOptions reads the ?round= and ?event= parameters, writing an
+// error response and returning false if either doesn't exist
+func resolveResultsOptions(w http.ResponseWriter, r *http.Request) (types.ResultsOptions, bool) {
+	var options types.ResultsOptions
+
+	round, err := resolveResultsRound(r)
+	if err != nil {
+		LogErrorf("Error resolving results round: %v", err)
+		http.Error(w, "Round not found", http.StatusNotFound)
+		return options, false
+	}
+	if round != nil {
+		options.RoundID = round.ID
+	}
+
+	event, err := resolveResultsEvent(r)
+	if err != nil {
+		LogErrorf("Error resolving results event: %v", err)
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return options, false
+	}
+	if event != nil {
+		options.EventID = event.ID
+	}
+	return options, true
+}
+
+// parseResultsQuery reads the sorting, filtering and paging parameters of a
+// results list request
+func parseResultsQuery(r *http.Request) (types.ResultsQuery, error) {
+	params := r.URL.Query()
+	query := types.ResultsQuery{
+		Sort:            params.Get("sort"),
+		Order:           params.Get("order"),
+		MaxSeenRatio:    1,
+		MaxPolarization: 1,
+		Page:            1,
+		PageSize:        defaultResultsPageSize,
+	}
+
+	if query.Order != "" && query.Order != "asc" && query.Order != "desc" {
+		return query, fmt.Errorf("invalid order %q", query.Order)
+	}
+
+	ints := []struct {
+		name  string
+		value *int
+		min   int
+	}{
+		{"year", &query.Year, 1},
+		{"min_participation", &query.MinParticipation, 0},
+		{"page", &query.Page, 1},
+		{"page_size", &query.PageSize, 1},
+	}
+	for _, param := range ints {
+		str := params.Get(param.name)
+		if str == "" {
+			continue
+		}
+		value, err := strconv.Atoi(str)
+		if err != nil || value < param.min {
+			return query, fmt.Errorf("invalid %s %q", param.name, str)
+		}
+		*param.value = value
+	}
+	if query.PageSize > maxResultsPageSize {
+		query.PageSize = maxResultsPageSize
+	}
+
+	fractions := []struct {
+		name  string
+		value *float64
+	}{
+		{"min_seen_ratio", &query.MinSeenRatio},
+		{"max_seen_ratio", &query.MaxSeenRatio},
+		{"min_polarization", &query.MinPolarization},
+		{"max_polarization", &query.MaxPolarization},
+	}
+	for _, param := range fractions {
+		str := params.Get(param.name)
+		if str == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(str, 64)
+		if err != nil || value < 0 || value > 1 {
+			return query, fmt.Errorf("invalid %s %q", param.name, str)
+		}
+		*param.value = value
+	}
+
+	return query, nil
+}
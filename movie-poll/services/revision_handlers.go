@@ -0,0 +1,107 @@
+package services
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/thornzero/movie-poll/models"
+	"github.com/thornzero/movie-poll/views"
+)
+
+// handleAdminVoteHistory handles the vote history page. ?movie=ID shows a
+// movie's timeline and ?user=NAME&device=ID shows a participant's.
+func (hr *HandlerRegistry) handleAdminVoteHistory(w http.ResponseWriter, r *http.Request) {
+	// Check if user is logged in as admin
+	sessionData := Session.GetSessionData(r)
+	if sessionData.AdminUser == nil {
+		http.Redirect(w, r, "/admin", http.StatusSeeOther)
+		return
+	}
+
+	movies, err := DB.GetMovies(0)
+	if err != nil {
+		LogErrorf("Error getting movies for vote history: %v", err)
+		http.Error(w, "Failed to load movies", http.StatusInternalServerError)
+		return
+	}
+
+	users, err := DB.GetUsers(0)
+	if err != nil {
+		LogErrorf("Error getting users for vote history: %v", err)
+		http.Error(w, "Failed to load users", http.StatusInternalServerError)
+		return
+	}
+
+	titles := make(map[uint]string, len(movies))
+	movieInfos := make([]views.MovieInfo, len(movies))
+	for i, movie := range movies {
+		titles[uint(movie.ID)] = movie.Title
+		movieInfos[i] = views.MovieInfo{
+			ID:      movie.ID,
+			Title:   movie.Title,
+			AddedAt: time.Unix(movie.AddedAt, 0),
+		}
+	}
+
+	userInfos := make([]views.VoteHistoryUser, len(users))
+	for i, user := range users {
+		userInfos[i] = views.VoteHistoryUser{UserName: user.UserName, DeviceID: user.DeviceID}
+	}
+
+	historyData := views.AdminVoteHistoryData{
+		AdminUser: views.AdminUserInfo{
+			ID:       sessionData.AdminUser.ID,
+			Username: sessionData.AdminUser.Username,
+		},
+		Movies: movieInfos,
+		Users:  userInfos,
+	}
+
+	query := r.URL.Query()
+	var revisions []models.VoteRevision
+	if movieStr := query.Get("movie"); movieStr != "" {
+		movieID, err := strconv.Atoi(movieStr)
+		if err != nil || movieID <= 0 {
+			http.Error(w, "Invalid movie ID", http.StatusBadRequest)
+			return
+		}
+		revisions, err = DB.GetMovieVoteTimeline(uint(movieID))
+		if err != nil {
+			LogErrorf("Error getting vote timeline for movie %d: %v", movieID, err)
+			http.Error(w, "Failed to load vote history", http.StatusInternalServerError)
+			return
+		}
+		historyData.Heading = titles[uint(movieID)]
+		if historyData.Heading == "" {
+			historyData.Heading = "Movie #" + movieStr
+		}
+	} else if userName := query.Get("user"); userName != "" {
+		revisions, err = DB.GetUserVoteTimeline(userName, query.Get("device"))
+		if err != nil {
+			LogErrorf("Error getting vote timeline for %s: %v", userName, err)
+			http.Error(w, "Failed to load vote history", http.StatusInternalServerError)
+			return
+		}
+		historyData.Heading = userName
+	}
+
+	for _, revision := range revisions {
+		title := titles[revision.MovieID]
+		if title == "" {
+			title = "Deleted movie #" + strconv.Itoa(int(revision.MovieID))
+		}
+		historyData.Revisions = append(historyData.Revisions, views.VoteRevisionInfo{
+			MovieTitle: title,
+			UserName:   revision.UserName,
+			OldVibe:    revision.OldVibe,
+			OldSeen:    revision.OldSeen,
+			NewVibe:    revision.NewVibe,
+			NewSeen:    revision.NewSeen,
+			Source:     revision.Source,
+			CreatedAt:  revision.CreatedAt,
+		})
+	}
+
+	views.AdminVoteHistoryPage(historyData).Render(r.Context(), w)
+}
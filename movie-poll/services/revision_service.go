@@ -0,0 +1,183 @@
+package services
+
+import (
+	"github.com/thornzero/movie-poll/models"
+	"gorm.io/gorm"
+)
+
+// Revision sources for changes that don't come from a voting endpoint
+const (
+	RevisionSourceImport     = "import"
+	RevisionSourceDeleteAll  = "delete-all-votes"
+	RevisionSourceDeleteUser = "delete-user"
+	RevisionSourceDuplicate  = "duplicate-movie"
+)
+
+type RevisionService struct {
+	db *gorm.DB
+}
+
+func NewRevisionService(db *gorm.DB) *RevisionService {
+	return &RevisionService{db: db}
+}
+
+// SeedFromVotes records the current votes as the start of the history when
+// no revisions exist yet, so a replay doesn't lose votes cast before
+// revisions were tracked
+func (s *RevisionService) SeedFromVotes() error {
+	var count int64
+	if err := s.db.Model(&models.VoteRevision{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	var votes []models.Vote
+	if err := s.db.Order("created_at, id").Find(&votes).Error; err != nil {
+		return err
+	}
+	for i := range votes {
+		revision := newVoteRevision(nil, &votes[i], RevisionSourceImport)
+		revision.CreatedAt = votes[i].UpdatedAt
+		if err := s.db.Create(&revision).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetMovieTimeline returns every change to votes on a movie, oldest first
+func (s *RevisionService) GetMovieTimeline(movieID uint) ([]models.VoteRevision, error) {
+	var revisions []models.VoteRevision
+	err := s.db.Where("movie_id = ?", movieID).Order("created_at, id").Find(&revisions).Error
+	return revisions, err
+}
+
+// GetUserTimeline returns every change to a participant's votes, oldest first
+func (s *RevisionService) GetUserTimeline(userName, deviceID string) ([]models.VoteRevision, error) {
+	var revisions []models.VoteRevision
+	err := s.db.Where("user_name = ? AND device_id = ?", userName, deviceID).
+		Order("created_at, id").
+		Find(&revisions).Error
+	return revisions, err
+}
+
+// ReplayRevisions rebuilds the votes table from the revision history and
+// returns the number of votes restored. Votes on movies that no longer exist
+// are skipped.
+func (s *RevisionService) ReplayRevisions() (int, error) {
+	type voteKey struct {
+		movieID  uint
+		userName string
+		deviceID string
+		roundID  uint
+	}
+
+	restored := 0
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var revisions []models.VoteRevision
+		if err := tx.Order("id").Find(&revisions).Error; err != nil {
+			return err
+		}
+
+		var movieIDs []uint
+		if err := tx.Model(&models.Movie{}).Pluck("id", &movieIDs).Error; err != nil {
+			return err
+		}
+		movieExists := make(map[uint]bool, len(movieIDs))
+		for _, id := range movieIDs {
+			movieExists[id] = true
+		}
+
+		// Fold the history into the latest state of each vote
+		votes := make(map[voteKey]*models.Vote)
+		listed := make(map[voteKey]bool)
+		var order []voteKey
+		for _, revision := range revisions {
+			key := voteKey{revision.MovieID, revision.UserName, revision.DeviceID, derefRoundID(revision.RoundID)}
+			if revision.IsDeletion() {
+				delete(votes, key)
+				continue
+			}
+
+			vote, ok := votes[key]
+			if !ok {
+				vote = &models.Vote{
+					MovieID:   revision.MovieID,
+					UserName:  revision.UserName,
+					DeviceID:  revision.DeviceID,
+					RoundID:   revision.RoundID,
+					CreatedAt: revision.CreatedAt,
+				}
+				votes[key] = vote
+				if !listed[key] {
+					listed[key] = true
+					order = append(order, key)
+				}
+			}
+			vote.Vibe = *revision.NewVibe
+			vote.Seen = revision.NewSeen != nil && *revision.NewSeen
+			vote.UpdatedAt = revision.CreatedAt
+		}
+
+		if err := tx.Where("1 = 1").Delete(&models.Vote{}).Error; err != nil {
+			return err
+		}
+		for _, key := range order {
+			vote, ok := votes[key]
+			if !ok || !movieExists[key.movieID] {
+				continue
+			}
+			if err := tx.Create(vote).Error; err != nil {
+				return err
+			}
+			restored++
+		}
+		return rebuildAllAppeals(tx)
+	})
+	return restored, err
+}
+
+// recordVoteRevision appends a revision for a vote going from before to
+// after. Either side may be nil for a new or deleted vote.
+func recordVoteRevision(tx *gorm.DB, before, after *models.Vote, source string) error {
+	revision := newVoteRevision(before, after, source)
+	return tx.Create(&revision).Error
+}
+
+// recordVoteDeletions appends a deletion revision for each vote
+func recordVoteDeletions(tx *gorm.DB, votes []models.Vote, source string) error {
+	for i := range votes {
+		if err := recordVoteRevision(tx, &votes[i], nil, source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newVoteRevision(before, after *models.Vote, source string) models.VoteRevision {
+	vote := after
+	if vote == nil {
+		vote = before
+	}
+
+	revision := models.VoteRevision{
+		MovieID:  vote.MovieID,
+		UserName: vote.UserName,
+		DeviceID: vote.DeviceID,
+		RoundID:  vote.RoundID,
+		Source:   source,
+	}
+	if before != nil {
+		vibe, seen := before.Vibe, before.Seen
+		revision.OldVibe = &vibe
+		revision.OldSeen = &seen
+	}
+	if after != nil {
+		vibe, seen := after.Vibe, after.Seen
+		revision.NewVibe = &vibe
+		revision.NewSeen = &seen
+	}
+	return revision
+}
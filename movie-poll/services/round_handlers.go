@@ -0,0 +1,194 @@
+package services
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/thornzero/movie-poll/models"
+	"github.com/thornzero/movie-poll/views"
+)
+
+// roundDateLayout matches the value format of <input type="datetime-local">
+const roundDateLayout = "2006-01-02T15:04"
+
+// handleAdminRounds handles the poll rounds admin page
+func (hr *HandlerRegistry) handleAdminRounds(w http.ResponseWriter, r *http.Request) {
+	// Check if user is logged in as admin
+	sessionData := Session.GetSessionData(r)
+	if sessionData.AdminUser == nil {
+		http.Redirect(w, r, "/admin", http.StatusSeeOther)
+		return
+	}
+
+	rounds, err := DB.GetRounds()
+	if err != nil {
+		LogErrorf("Error getting rounds: %v", err)
+		http.Error(w, "Failed to load rounds", http.StatusInternalServerError)
+		return
+	}
+
+	movieInfos, err := getSlateMovieInfos(r)
+	if err != nil {
+		LogErrorf("Error getting movies for rounds: %v", err)
+		http.Error(w, "Failed to load movies", http.StatusInternalServerError)
+		return
+	}
+
+	roundsData := views.AdminRoundsData{
+		AdminUser: views.AdminUserInfo{
+			ID:       sessionData.AdminUser.ID,
+			Username: sessionData.AdminUser.Username,
+		},
+		Rounds:          convertRoundsToInfo(rounds),
+		Movies:          movieInfos,
+		IncludeScreened: includeScreened(r),
+	}
+
+	views.AdminRoundsPage(roundsData).Render(r.Context(), w)
+}
+
+// handleAdminCreateRound handles creating a new poll round
+func (hr *HandlerRegistry) handleAdminCreateRound(w http.ResponseWriter, r *http.Request) {
+	// Check if user is logged in as admin
+	sessionData := Session.GetSessionData(r)
+	if sessionData.AdminUser == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+
+	name := r.FormValue("name")
+	if name == "" {
+		http.Error(w, "Round name is required", http.StatusBadRequest)
+		return
+	}
+
+	var movieIDs []uint
+	for _, idStr := range r.Form["movie_ids"] {
+		id, err := strconv.Atoi(idStr)
+		if err != nil || id <= 0 {
+			http.Error(w, "Invalid movie ID", http.StatusBadRequest)
+			return
+		}
+		movieIDs = append(movieIDs, uint(id))
+	}
+	if len(movieIDs) == 0 {
+		http.Error(w, "Pick at least one movie for the slate", http.StatusBadRequest)
+		return
+	}
+
+	opensAt, err := parseRoundTime(r.FormValue("opens_at"))
+	if err != nil {
+		http.Error(w, "Invalid opening time", http.StatusBadRequest)
+		return
+	}
+	closesAt, err := parseRoundTime(r.FormValue("closes_at"))
+	if err != nil {
+		http.Error(w, "Invalid closing time", http.StatusBadRequest)
+		return
+	}
+
+	_, err = DB.CreateRound(name, movieIDs, opensAt, closesAt)
+	if err != nil {
+		LogErrorf("Error creating round %s: %v", name, err)
+		http.Error(w, "Failed to create round", http.StatusInternalServerError)
+		return
+	}
+
+	respondRoundsChanged(w, r)
+}
+
+// handleAdminOpenRound handles opening a poll round for voting
+func (hr *HandlerRegistry) handleAdminOpenRound(w http.ResponseWriter, r *http.Request) {
+	hr.updateRoundStatus(w, r, DB.OpenRound)
+}
+
+// handleAdminCloseRound handles closing a poll round
+func (hr *HandlerRegistry) handleAdminCloseRound(w http.ResponseWriter, r *http.Request) {
+	hr.updateRoundStatus(w, r, DB.CloseRound)
+}
+
+// updateRoundStatus applies an open/close action to the round in the URL
+func (hr *HandlerRegistry) updateRoundStatus(w http.ResponseWriter, r *http.Request, action func(id uint) error) {
+	// Check if user is logged in as admin
+	sessionData := Session.GetSessionData(r)
+	if sessionData.AdminUser == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	roundID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || roundID <= 0 {
+		http.Error(w, "Invalid round ID", http.StatusBadRequest)
+		return
+	}
+
+	err = action(uint(roundID))
+	if err != nil {
+		LogErrorf("Error updating round %d: %v", roundID, err)
+		http.Error(w, "Failed to update round", http.StatusInternalServerError)
+		return
+	}
+
+	respondRoundsChanged(w, r)
+}
+
+// respondRoundsChanged re-renders the rounds list for HTMX requests and
+// redirects everything else back to the rounds page
+func respondRoundsChanged(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("HX-Request") != "true" {
+		http.Redirect(w, r, "/admin/rounds", http.StatusSeeOther)
+		return
+	}
+
+	rounds, err := DB.GetRounds()
+	if err != nil {
+		LogErrorf("Error getting rounds: %v", err)
+		http.Error(w, "Failed to load rounds", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Trigger", "roundsChanged")
+	views.RoundsList(convertRoundsToInfo(rounds)).Render(r.Context(), w)
+}
+
+// parseRoundTime parses an optional datetime-local form value
+func parseRoundTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.ParseInLocation(roundDateLayout, value, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// convertRoundsToInfo converts rounds to their display form
+func convertRoundsToInfo(rounds []models.PollRound) []views.RoundInfo {
+	now := time.Now()
+	infos := make([]views.RoundInfo, len(rounds))
+	for i, round := range rounds {
+		titles := make([]string, len(round.Movies))
+		for j, movie := range round.Movies {
+			titles[j] = movie.Title
+		}
+		infos[i] = views.RoundInfo{
+			ID:          int(round.ID),
+			Name:        round.Name,
+			Status:      round.Status,
+			AcceptsVote: round.IsOpenAt(now),
+			OpensAt:     round.OpensAt,
+			ClosesAt:    round.ClosesAt,
+			MovieTitles: titles,
+		}
+	}
+	return infos
+}
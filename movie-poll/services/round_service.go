@@ -0,0 +1,216 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/thornzero/movie-poll/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrNoOpenRound     = errors.New("no poll round is open for voting")
+	ErrRoundClosed     = errors.New("poll round is closed")
+	ErrMovieNotInRound = errors.New("movie is not on this round's slate")
+)
+
+type RoundService struct {
+	db *gorm.DB
+}
+
+func NewRoundService(db *gorm.DB) *RoundService {
+	return &RoundService{db: db}
+}
+
+// CreateRound creates a draft round with the given slate of movies
+func (s *RoundService) CreateRound(name string, movieIDs []uint, opensAt, closesAt *time.Time) (*models.PollRound, error) {
+	round := models.PollRound{
+		Name:     name,
+		Status:   models.PollRoundDraft,
+		OpensAt:  opensAt,
+		ClosesAt: closesAt,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if len(movieIDs) > 0 {
+			if err := tx.Find(&round.Movies, movieIDs).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(&round).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &round, nil
+}
+
+// GetRounds returns all rounds, newest first
+func (s *RoundService) GetRounds() ([]models.PollRound, error) {
+	var rounds []models.PollRound
+	err := s.db.Preload("Movies").Order("created_at DESC").Find(&rounds).Error
+	return rounds, err
+}
+
+// GetRound returns a round with its slate
+func (s *RoundService) GetRound(id uint) (*models.PollRound, error) {
+	var round models.PollRound
+	err := s.db.Preload("Movies").First(&round, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &round, nil
+}
+
+// OpenRound opens a round for voting and closes any other open round
+func (s *RoundService) OpenRound(id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var round models.PollRound
+		if err := tx.First(&round, id).Error; err != nil {
+			return err
+		}
+
+		err := tx.Model(&models.PollRound{}).
+			Where("status = ? AND id <> ?", models.PollRoundOpen, id).
+			Update("status", models.PollRoundClosed).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&round).Update("status", models.PollRoundOpen).Error
+	})
+}
+
+// CloseRound stops a round from accepting votes
+func (s *RoundService) CloseRound(id uint) error {
+	result := s.db.Model(&models.PollRound{}).Where("id = ?", id).Update("status", models.PollRoundClosed)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetCurrentRound returns the round currently accepting votes, or nil
+func (s *RoundService) GetCurrentRound() (*models.PollRound, error) {
+	var rounds []models.PollRound
+	err := s.db.Preload("Movies").
+		Where("status = ?", models.PollRoundOpen).
+		Order("created_at DESC").
+		Find(&rounds).Error
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range rounds {
+		if rounds[i].IsOpenAt(now) {
+			return &rounds[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// GetResultsRound returns the round results should default to: the open
+// round if there is one, otherwise the most recently closed round. Returns
+// nil if rounds have never been used.
+func (s *RoundService) GetResultsRound() (*models.PollRound, error) {
+	current, err := s.GetCurrentRound()
+	if err != nil || current != nil {
+		return current, err
+	}
+
+	var round models.PollRound
+	err = s.db.Preload("Movies").
+		Where("status <> ?", models.PollRoundDraft).
+		Order("updated_at DESC").
+		First(&round).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &round, nil
+}
+
+// ResolveVoteRound works out which round a vote on movieID belongs to.
+// An explicit roundID must be open and contain the movie; otherwise the
+// current round is used. Votes are left unscoped only if no round has
+// ever been opened. Call it on a service made from the transaction that
+// writes the vote: the round stays share-locked until that commits, so it
+// can't close in between.
+func (s *RoundService) ResolveVoteRound(roundID, movieID uint) (*uint, error) {
+	var round *models.PollRound
+	var err error
+	locked := NewRoundService(s.db.Clauses(clause.Locking{Strength: "SHARE"}).Session(&gorm.Session{}))
+
+	if roundID > 0 {
+		round, err = locked.GetRound(roundID)
+		if err != nil {
+			return nil, err
+		}
+		if !round.IsOpenAt(time.Now()) {
+			return nil, ErrRoundClosed
+		}
+	} else {
+		round, err = locked.GetCurrentRound()
+		if err != nil {
+			return nil, err
+		}
+		if round == nil {
+			inUse, err := s.RoundsInUse()
+			if err != nil {
+				return nil, err
+			}
+			if inUse {
+				return nil, ErrNoOpenRound
+			}
+			return nil, nil
+		}
+	}
+
+	if !round.HasMovie(movieID) {
+		return nil, ErrMovieNotInRound
+	}
+	return &round.ID, nil
+}
+
+// RoundsInUse reports whether a round has ever been opened. From then on
+// votes must belong to an open round, so there is nothing to vote on
+// between rounds.
+func (s *RoundService) RoundsInUse() (bool, error) {
+	var count int64
+	err := s.db.Model(&models.PollRound{}).Where("status <> ?", models.PollRoundDraft).Count(&count).Error
+	return count > 0, err
+}
+
+// scopeRound restricts a query on a table with a round_id column to one
+// round. A zero roundID selects rows recorded before rounds were used.
+func scopeRound(roundID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if roundID == 0 {
+			return db.Where("round_id IS NULL")
+		}
+		return db.Where("round_id = ?", roundID)
+	}
+}
+
+// roundIDPtr converts a round ID to the nullable column form
+func roundIDPtr(roundID uint) *uint {
+	if roundID == 0 {
+		return nil
+	}
+	return &roundID
+}
+
+// derefRoundID returns the round ID or 0 for unscoped rows
+func derefRoundID(roundID *uint) uint {
+	if roundID == nil {
+		return 0
+	}
+	return *roundID
+}
@@ -34,7 +34,6 @@ func (rs *RouterService) SetupRoutes() *chi.Mux {
 	r.Use(middleware.Compress(5))                // Enable gzip compression
 	r.Use(middleware.Heartbeat("/ping"))         // Health check endpoint
 	r.Use(middleware.NoCache)                    // Prevent caching of sensitive endpoints
-	r.Use(middleware.Throttle(100))              // Limit to 100 requests per second
 	r.Use(httprate.LimitByIP(60, 1*time.Minute)) // 60 requests per minute per IP
 
 	// CORS middleware
@@ -47,78 +46,159 @@ func (rs *RouterService) SetupRoutes() *chi.Mux {
 		MaxAge:           300, // Maximum value not ignored by any of major browsers
 	}))
 
-	// Static file handlers with caching
-	r.Handle("/static/*", http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
-	r.Handle("/css/*", http.StripPrefix("/css/", http.FileServer(http.Dir("static/css"))))
-	r.Handle("/js/*", http.StripPrefix("/js/", http.FileServer(http.Dir("static/js"))))
-	r.Handle("/img/*", http.StripPrefix("/img/", http.FileServer(http.Dir("static/img"))))
-	r.Handle("/site.webmanifest", http.FileServer(http.Dir("static")))
-
-	// Favicon with caching (override NoCache for this specific route)
-	r.Get("/favicon.ico", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Cache-Control", "public, max-age=31536000") // 1 year
-		http.ServeFile(w, r, "static/img/favicon-32x32.png")
+	// Event streams stay open as long as their page does, so they're
+	// throttled apart from everything else rather than holding its slots
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Throttle(maxLiveStreams))
+		r.Get("/results/live", rs.registry.Get("results-live"))
+		r.Get("/live/stream", rs.registry.Get("live-stream"))
+		r.Get("/display/stream", rs.registry.Get("display-stream"))
+		r.Get("/admin/live/stream", rs.registry.Get("admin-live-stream"))
 	})
 
-	// Main application routes
-	r.Get("/", rs.registry.Get("home"))
-	r.Get("/results", rs.registry.Get("results"))
-	r.Get("/test", rs.registry.Get("test"))
-
-	// Admin routes
-	r.Get("/admin", rs.registry.Get("admin-login"))
-	r.Post("/api/admin/login", rs.registry.Get("admin-login-submit"))
-	r.Get("/admin/dashboard", rs.registry.Get("admin-dashboard"))
-	r.Get("/admin/movies", rs.registry.Get("admin-movies"))
-	r.Post("/api/admin/logout", rs.registry.Get("admin-logout"))
-	r.Post("/api/admin/cleanup-duplicates", rs.registry.Get("admin-cleanup-duplicates"))
-	r.Post("/api/admin/reset-database", rs.registry.Get("admin-reset-database"))
-	r.Get("/api/admin/votes", rs.registry.Get("admin-list-votes"))
-	r.Post("/api/admin/delete-all-votes", rs.registry.Get("admin-delete-all-votes"))
-	r.Delete("/api/admin/movies/{id}", rs.registry.Get("admin-delete-movie"))
-	r.Post("/api/admin/import-movies", rs.registry.Get("import-movies"))
-
-	// User management routes
-	r.Get("/admin/users", rs.registry.Get("admin-users"))
-	r.Get("/api/admin/users", rs.registry.Get("admin-users-api"))
-	r.Get("/api/admin/user-stats", rs.registry.Get("admin-user-stats"))
-	r.Post("/api/admin/user-delete", rs.registry.Get("admin-user-delete"))
-	r.Post("/api/admin/user-update-stats", rs.registry.Get("admin-user-update-stats"))
-
-	// Debug routes
-	r.Get("/debug", rs.registry.Get("debug"))
-	r.Get("/debug/session", rs.registry.Get("debug-session"))
-	r.Post("/debug/submit-results", rs.registry.Get("debug-submit-results"))
-	r.Get("/debug-page", rs.registry.Get("debug-page"))
-
-	// API routes
-	r.Route("/api", func(r chi.Router) {
-		// Core voting API
-		r.Get("/movies", rs.registry.Get("movies"))
-		r.Post("/vote", rs.registry.Get("vote"))
-		r.Post("/batch-vote", rs.registry.Get("batch-vote"))
-		r.Post("/start-poll", rs.registry.Get("start-poll"))
-		r.Post("/validate-username", rs.registry.Get("validate-username"))
-		r.Post("/check-name-similarity", rs.registry.Get("check-name-similarity"))
-		r.Post("/confirm-name", rs.registry.Get("confirm-name"))
-		r.Get("/search", rs.registry.Get("search"))
-		r.Post("/update-appeal", rs.registry.Get("update-appeal"))
-
-		// Voting flow API
-		r.Post("/voting/seen", rs.registry.Get("voting-seen"))
-		r.Post("/voting/rating", rs.registry.Get("voting-rating"))
-		r.Post("/voting/interest", rs.registry.Get("voting-interest"))
-		r.Post("/voting/next-movie", rs.registry.Get("voting-next-movie"))
-		r.Post("/voting/change-vote", rs.registry.Get("voting-change-vote"))
-
-		// Results API
-		r.Get("/results-summary", rs.registry.Get("results-summary"))
-		r.Get("/results-list", rs.registry.Get("results-list"))
-		r.Post("/logout", rs.registry.Get("logout"))
-
-		// Movie management API
-		r.Post("/add-movie", rs.registry.Get("add-movie"))
-		r.Post("/admin/add-movie", rs.registry.Get("add-movie"))
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Throttle(100)) // Limit to 100 requests in flight
+
+		// Static file handlers with caching
+		r.Handle("/static/*", http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
+		r.Handle("/css/*", http.StripPrefix("/css/", http.FileServer(http.Dir("static/css"))))
+		r.Handle("/js/*", http.StripPrefix("/js/", http.FileServer(http.Dir("static/js"))))
+		r.Handle("/img/*", http.StripPrefix("/img/", http.FileServer(http.Dir("static/img"))))
+		r.Handle("/site.webmanifest", http.FileServer(http.Dir("static")))
+
+		// Favicon with caching (override NoCache for this specific route)
+		r.Get("/favicon.ico", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Cache-Control", "public, max-age=31536000") // 1 year
+			http.ServeFile(w, r, "static/img/favicon-32x32.png")
+		})
+
+		// Main application routes
+		r.Get("/", rs.registry.Get("home"))
+		r.Get("/results", rs.registry.Get("results"))
+		r.Get("/results/trend/{id}", rs.registry.Get("appeal-trend"))
+		r.Get("/live", rs.registry.Get("live"))
+		r.Get("/display", rs.registry.Get("display"))
+		r.Get("/ranked", rs.registry.Get("ranked-ballot"))
+		r.Get("/events", rs.registry.Get("events"))
+		r.Get("/nominate", rs.registry.Get("nominate"))
+		r.Get("/link-device", rs.registry.Get("link-device"))
+		r.Get("/test", rs.registry.Get("test"))
+
+		// Admin routes
+		r.Get("/admin", rs.registry.Get("admin-login"))
+		r.Post("/api/admin/login", rs.registry.Get("admin-login-submit"))
+		r.Get("/admin/dashboard", rs.registry.Get("admin-dashboard"))
+		r.Get("/admin/movies", rs.registry.Get("admin-movies"))
+		r.Post("/api/admin/logout", rs.registry.Get("admin-logout"))
+		r.Post("/api/admin/cleanup-duplicates", rs.registry.Get("admin-cleanup-duplicates"))
+		r.Post("/api/admin/reset-database", rs.registry.Get("admin-reset-database"))
+		r.Get("/api/admin/votes", rs.registry.Get("admin-list-votes"))
+		r.Get("/admin/votes/history", rs.registry.Get("admin-vote-history"))
+		r.Post("/api/admin/delete-all-votes", rs.registry.Get("admin-delete-all-votes"))
+		r.Delete("/api/admin/movies/{id}", rs.registry.Get("admin-delete-movie"))
+		r.Post("/api/admin/import-movies", rs.registry.Get("import-movies"))
+
+		// Poll round routes
+		r.Get("/admin/rounds", rs.registry.Get("admin-rounds"))
+		r.Post("/api/admin/rounds", rs.registry.Get("admin-create-round"))
+		r.Post("/api/admin/rounds/{id}/open", rs.registry.Get("admin-open-round"))
+		r.Post("/api/admin/rounds/{id}/close", rs.registry.Get("admin-close-round"))
+
+		// Event routes
+		r.Get("/admin/events", rs.registry.Get("admin-events"))
+		r.Post("/api/admin/events", rs.registry.Get("admin-create-event"))
+		r.Post("/api/admin/events/{id}/choose", rs.registry.Get("admin-choose-event-movie"))
+		r.Delete("/api/admin/events/{id}", rs.registry.Get("admin-delete-event"))
+
+		// Nomination routes
+		r.Post("/api/admin/nominations/{id}/approve", rs.registry.Get("admin-approve-nomination"))
+		r.Post("/api/admin/nominations/{id}/reject", rs.registry.Get("admin-reject-nomination"))
+
+		// Screening routes
+		r.Post("/api/admin/movies/{id}/screened", rs.registry.Get("admin-mark-movie-screened"))
+		r.Post("/api/admin/events/{id}/screened", rs.registry.Get("admin-mark-event-screened"))
+
+		// Appeal strategy routes
+		r.Post("/api/admin/appeal-strategy", rs.registry.Get("admin-set-appeal-strategy"))
+		r.Post("/api/admin/rebuild-appeals", rs.registry.Get("admin-rebuild-appeals"))
+		r.Post("/api/admin/appeal-snapshots", rs.registry.Get("admin-take-appeal-snapshot"))
+
+		// Results export routes
+		r.Get("/admin/results/export", rs.registry.Get("admin-export-results"))
+
+		// Taste similarity routes
+		r.Get("/admin/taste", rs.registry.Get("admin-taste"))
+		r.Get("/api/admin/taste", rs.registry.Get("admin-taste-api"))
+
+		// Live vote routes
+		r.Get("/admin/live", rs.registry.Get("admin-live"))
+		r.Post("/api/admin/live/{action}", rs.registry.Get("admin-live-action"))
+
+		// User management routes
+		r.Get("/admin/users", rs.registry.Get("admin-users"))
+		r.Get("/api/admin/users", rs.registry.Get("admin-users-api"))
+		r.Get("/api/admin/user-stats", rs.registry.Get("admin-user-stats"))
+		r.Post("/api/admin/user-delete", rs.registry.Get("admin-user-delete"))
+		r.Post("/api/admin/user-update-stats", rs.registry.Get("admin-user-update-stats"))
+
+		// Identity merge routes
+		r.Get("/admin/users/merge", rs.registry.Get("admin-merge-preview"))
+		r.Post("/api/admin/users/merge", rs.registry.Get("admin-merge-identities"))
+		r.Get("/admin/users/merges", rs.registry.Get("admin-merges"))
+		r.Post("/api/admin/users/merges/{id}/undo", rs.registry.Get("admin-undo-merge"))
+
+		// Debug routes
+		r.Get("/debug", rs.registry.Get("debug"))
+		r.Get("/debug/session", rs.registry.Get("debug-session"))
+		r.Post("/debug/submit-results", rs.registry.Get("debug-submit-results"))
+		r.Get("/debug-page", rs.registry.Get("debug-page"))
+
+		// API routes
+		r.Route("/api", func(r chi.Router) {
+			// Core voting API
+			r.Get("/movies", rs.registry.Get("movies"))
+			r.Post("/vote", rs.registry.Get("vote"))
+			r.Post("/batch-vote", rs.registry.Get("batch-vote"))
+			r.Post("/start-poll", rs.registry.Get("start-poll"))
+			r.Post("/validate-username", rs.registry.Get("validate-username"))
+			r.Post("/check-name-similarity", rs.registry.Get("check-name-similarity"))
+			r.Post("/confirm-name", rs.registry.Get("confirm-name"))
+			r.Get("/search", rs.registry.Get("search"))
+			r.Post("/update-appeal", rs.registry.Get("update-appeal"))
+
+			// Voting flow API
+			r.Post("/voting/seen", rs.registry.Get("voting-seen"))
+			r.Post("/voting/rating", rs.registry.Get("voting-rating"))
+			r.Post("/voting/interest", rs.registry.Get("voting-interest"))
+			r.Post("/voting/next-movie", rs.registry.Get("voting-next-movie"))
+			r.Post("/voting/change-vote", rs.registry.Get("voting-change-vote"))
+			r.Post("/voting/veto", rs.registry.Get("voting-veto"))
+			r.Post("/voting/withdraw-veto", rs.registry.Get("voting-withdraw-veto"))
+
+			// Ranked-choice API
+			r.Post("/ranked-ballot", rs.registry.Get("submit-ranked-ballot"))
+
+			// Nomination API
+			r.Get("/nominations/search", rs.registry.Get("nomination-search"))
+			r.Post("/nominations", rs.registry.Get("submit-nomination"))
+
+			// Device pairing API, with code guesses kept to a trickle
+			r.Post("/pairing-code", rs.registry.Get("create-pairing-code"))
+			r.With(httprate.LimitByIP(5, time.Minute)).Post("/pairing-code/claim", rs.registry.Get("claim-pairing-code"))
+
+			// Event RSVP API
+			r.Post("/events/{id}/rsvp", rs.registry.Get("event-rsvp"))
+
+			// Results API
+			r.Get("/results-summary", rs.registry.Get("results-summary"))
+			r.Get("/results-list", rs.registry.Get("results-list"))
+			r.Post("/logout", rs.registry.Get("logout"))
+
+			// Movie management API
+			r.Post("/add-movie", rs.registry.Get("add-movie"))
+			r.Post("/admin/add-movie", rs.registry.Get("add-movie"))
+		})
+
 	})
 
 	return r
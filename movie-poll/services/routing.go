@@ -1,6 +1,7 @@
 package services
 
 import (
+	"errors"
 	"log"
 	"net/http"
 
@@ -34,8 +35,12 @@ func (h *BasicHandlers) HandleHome(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get movies from database
-	movies, err := DB.GetMovies(Config.MovieLimit)
+	// Get movies in this user's voting order
+	movies, err := getVotingSequence(sessionData)
+	if errors.Is(err, ErrNoOpenRound) {
+		views.NoOpenRoundPage(sessionData.UserName).Render(r.Context(), w)
+		return
+	}
 	if err != nil {
 		log.Printf("Error fetching movies: %v", err)
 		http.Error(w, "Failed to load movies", http.StatusInternalServerError)
@@ -43,20 +48,19 @@ func (h *BasicHandlers) HandleHome(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Render the movie poll page
-	renderMoviePollPage(w, r, movies, sessionData)
+	renderMoviePollPage(w, r, movies, sessionData, nextUnvotedIndex(movies, sessionData.Votes, -1))
 }
 
 // HandleResults serves the results page
 func (h *BasicHandlers) HandleResults(w http.ResponseWriter, r *http.Request) {
-	// Calculate appeal scores first
-	err := DB.CalculateAppealScores()
-	if err != nil {
-		log.Printf("Error calculating appeal scores: %v", err)
-		// Continue anyway - we'll show what we have
+	// Results default to the open round, or the last one to close
+	var roundID uint
+	if round, err := DB.GetResultsRound(); err == nil && round != nil {
+		roundID = round.ID
 	}
 
 	// Get results data
-	votingSummary, err := DB.GetResultsSummary()
+	votingSummary, err := DB.GetResultsSummary(types.ResultsOptions{RoundID: roundID})
 	if err != nil {
 		log.Printf("Error fetching results summary: %v", err)
 		http.Error(w, "Failed to load results", http.StatusInternalServerError)
@@ -64,7 +68,7 @@ func (h *BasicHandlers) HandleResults(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get voting statistics
-	stats, err := DB.GetVotingStats()
+	stats, err := DB.GetVotingStats(roundID)
 	if err != nil {
 		log.Printf("Error fetching voting stats: %v", err)
 		// Continue with empty stats
@@ -73,8 +77,9 @@ func (h *BasicHandlers) HandleResults(w http.ResponseWriter, r *http.Request) {
 
 	// Create results data
 	resultsData := views.ResultsData{
-		Movies: votingSummary,
-		Stats:  *stats,
+		Movies:   votingSummary,
+		Stats:    *stats,
+		Strategy: convertAppealStrategyToInfo(DB.GetActiveAppealStrategy(), true),
 	}
 
 	// Render the results page
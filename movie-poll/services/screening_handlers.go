@@ -0,0 +1,132 @@
+package services
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/thornzero/movie-poll/models"
+	"github.com/thornzero/movie-poll/views"
+	"gorm.io/gorm"
+)
+
+// handleAdminMarkMovieScreened handles retiring a movie that was watched
+// outside of a scheduled event
+func (hr *HandlerRegistry) handleAdminMarkMovieScreened(w http.ResponseWriter, r *http.Request) {
+	// Check if user is logged in as admin
+	sessionData := Session.GetSessionData(r)
+	if sessionData.AdminUser == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	movieID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || movieID <= 0 {
+		http.Error(w, "Invalid movie ID", http.StatusBadRequest)
+		return
+	}
+
+	screening, err := DB.RecordScreening(uint(movieID), time.Now(), nil, nil, false)
+	if err == gorm.ErrRecordNotFound {
+		http.Error(w, "Movie not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		LogErrorf("Error recording screening of movie %d: %v", movieID, err)
+		http.Error(w, "Failed to record screening", http.StatusInternalServerError)
+		return
+	}
+
+	if r.Header.Get("HX-Request") != "true" {
+		http.Redirect(w, r, "/admin/movies", http.StatusSeeOther)
+		return
+	}
+
+	movie, err := DB.GetMovieByID(movieID)
+	if err != nil {
+		LogErrorf("Error getting movie %d: %v", movieID, err)
+		http.Error(w, "Failed to load movie", http.StatusInternalServerError)
+		return
+	}
+	year := 0
+	if movie.Year != nil {
+		year = *movie.Year
+	}
+	views.AdminMoviesMovieCard(views.MovieInfo{
+		ID:         movie.ID,
+		Title:      movie.Title,
+		Year:       year,
+		AddedAt:    time.Unix(movie.AddedAt, 0),
+		ScreenedAt: &screening.ScreenedAt,
+	}).Render(r.Context(), w)
+}
+
+// handleAdminMarkEventScreened handles recording that an event's chosen movie
+// was watched by the people who RSVP'd "going"
+func (hr *HandlerRegistry) handleAdminMarkEventScreened(w http.ResponseWriter, r *http.Request) {
+	hr.updateEvent(w, r, func(eventID uint) error {
+		event, err := DB.GetEvent(eventID)
+		if err != nil {
+			return err
+		}
+		if event.ChosenMovieID == nil {
+			return ErrNoChosenMovie
+		}
+
+		var attendees []models.ScreeningAttendee
+		for _, rsvp := range event.RSVPs {
+			if rsvp.Status == models.RSVPGoing {
+				attendees = append(attendees, models.ScreeningAttendee{
+					UserName: rsvp.UserName,
+					DeviceID: rsvp.DeviceID,
+				})
+			}
+		}
+
+		markSeen := r.FormValue("mark_seen") == "true"
+		_, err = DB.RecordScreening(*event.ChosenMovieID, event.StartsAt, &event.ID, attendees, markSeen)
+		return err
+	})
+}
+
+// getSlateMovieInfos returns the movies an admin can put on a new slate.
+// Screened movies are left out unless the page asks for ?screened=include.
+func getSlateMovieInfos(r *http.Request) ([]views.MovieInfo, error) {
+	getMovies := DB.GetUnscreenedMovies
+	if includeScreened(r) {
+		getMovies = DB.GetMovies
+	}
+	movies, err := getMovies(0)
+	if err != nil {
+		return nil, err
+	}
+
+	screened, err := DB.GetScreeningDates()
+	if err != nil {
+		return nil, err
+	}
+
+	movieInfos := make([]views.MovieInfo, len(movies))
+	for i, movie := range movies {
+		year := 0
+		if movie.Year != nil {
+			year = *movie.Year
+		}
+		movieInfos[i] = views.MovieInfo{
+			ID:      movie.ID,
+			Title:   movie.Title,
+			Year:    year,
+			AddedAt: time.Unix(movie.AddedAt, 0),
+		}
+		if screenedAt, ok := screened[uint(movie.ID)]; ok {
+			movieInfos[i].ScreenedAt = &screenedAt
+		}
+	}
+	return movieInfos, nil
+}
+
+// includeScreened reports whether a slate picker should offer screened movies
+func includeScreened(r *http.Request) bool {
+	return r.URL.Query().Get("screened") == "include"
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"time"
+
+	"github.com/thornzero/movie-poll/models"
+	"gorm.io/gorm"
+)
+
+// RevisionSourceScreening marks votes flipped to seen by a screening
+const RevisionSourceScreening = "screening"
+
+type ScreeningService struct {
+	db *gorm.DB
+}
+
+func NewScreeningService(db *gorm.DB) *ScreeningService {
+	return &ScreeningService{db: db}
+}
+
+// RecordScreening records that a movie was watched. With markSeen, each
+// attendee's not-seen votes on the movie are flipped to seen; their original
+// interest vote stays in the vote history.
+func (s *ScreeningService) RecordScreening(movieID uint, screenedAt time.Time, eventID *uint, attendees []models.ScreeningAttendee, markSeen bool) (*models.Screening, error) {
+	screening := models.Screening{
+		MovieID:    movieID,
+		EventID:    eventID,
+		ScreenedAt: screenedAt,
+		Attendees:  attendees,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&models.Movie{}, movieID).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&screening).Error; err != nil {
+			return err
+		}
+		if !markSeen {
+			return nil
+		}
+
+		var changed []models.Vote
+		for _, attendee := range attendees {
+			var votes []models.Vote
+			err := tx.Where("movie_id = ? AND user_name = ? AND device_id = ? AND seen = ?",
+				movieID, attendee.UserName, attendee.DeviceID, false).
+				Find(&votes).Error
+			if err != nil {
+				return err
+			}
+			for _, vote := range votes {
+				before := vote
+				vote.Seen = true
+				if err := tx.Save(&vote).Error; err != nil {
+					return err
+				}
+				if err := recordVoteRevision(tx, &before, &vote, RevisionSourceScreening); err != nil {
+					return err
+				}
+				changed = append(changed, vote)
+			}
+		}
+		return refreshVoteAppeals(tx, changed)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &screening, nil
+}
+
+// GetScreeningDates returns when each screened movie was last shown
+func (s *ScreeningService) GetScreeningDates() (map[uint]time.Time, error) {
+	var screenings []models.Screening
+	if err := s.db.Order("screened_at").Find(&screenings).Error; err != nil {
+		return nil, err
+	}
+
+	dates := make(map[uint]time.Time, len(screenings))
+	for _, screening := range screenings {
+		dates[screening.MovieID] = screening.ScreenedAt
+	}
+	return dates, nil
+}
@@ -24,6 +24,8 @@ var Config *EnvConfig
 var Handlers *BasicHandlers
 var Registry *HandlerRegistry
 var Router *RouterService
+var LiveResults *UpdateBroker
+var Live *LiveSession
 
 func InitServices() error {
 	var err error
@@ -55,6 +57,8 @@ func InitServices() error {
 	}
 
 	Handlers = NewBasicHandlers()
+	LiveResults = NewUpdateBroker()
+	Live = NewLiveSession()
 	Registry = NewHandlerRegistry()
 	Router = NewRouterService(Registry)
 	return nil
@@ -209,6 +213,9 @@ func StartServer(handler http.Handler) {
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	// Live results streams never end on their own
+	server.RegisterOnShutdown(LiveResults.Close)
+	server.RegisterOnShutdown(Live.Updates.Close)
 
 	// Start server in a goroutine
 	go func() {
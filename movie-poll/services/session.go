@@ -17,10 +17,23 @@ type SessionManager struct {
 
 // SessionData represents data stored in the session
 type SessionData struct {
-	UserName  string             `json:"user_name"`
-	DeviceID  string             `json:"device_id"`
-	Votes     map[int]types.Vote `json:"votes"` // movie_id -> vote
-	AdminUser *AdminUserInfo     `json:"admin_user,omitempty"`
+	UserName        string             `json:"user_name"`
+	DeviceID        string             `json:"device_id"`
+	LinkedDeviceID  string             `json:"linked_device_id,omitempty"` // device this one shares votes with, see VoterDeviceID
+	Votes           map[int]types.Vote `json:"votes"`                      // movie_id -> vote
+	RoundID         uint               `json:"round_id"`                   // round the Votes map belongs to
+	MergeGeneration string             `json:"merge_generation,omitempty"` // identity merges the name and Votes reflect, see loadSessionVotes
+	AdminUser       *AdminUserInfo     `json:"admin_user,omitempty"`
+}
+
+// VoterDeviceID returns the device ID the session's votes, vetoes, ballots
+// and RSVPs are kept under. That's this device's own ID unless a pairing
+// code linked it to another device, whose ID it then votes under.
+func (d *SessionData) VoterDeviceID() string {
+	if d.LinkedDeviceID != "" {
+		return d.LinkedDeviceID
+	}
+	return d.DeviceID
 }
 
 // AdminUserInfo represents admin user info in session
@@ -86,7 +99,9 @@ func (s *SessionManager) GetSessionData(r *http.Request) *SessionData {
 	if data, ok := sessionData.(*SessionData); ok {
 		// Update last seen timestamp for this device
 		if DB != nil {
-			DB.UpdateDeviceLastSeen(data.DeviceID)
+			if err := DB.TouchDevice(data.DeviceID, r.UserAgent()); err != nil {
+				LogErrorf("Error updating device %s: %v", data.DeviceID, err)
+			}
 		}
 		return data
 	}
@@ -113,3 +128,98 @@ func (s *SessionManager) GetSessionData(r *http.Request) *SessionData {
 func (s *SessionManager) PutSessionData(r *http.Request, data *SessionData) {
 	s.Put(r.Context(), "data", data)
 }
+
+// SyncSessionRound reloads the session's votes when the open poll round has
+// changed since they were cached, so a new round starts with a clean slate
+func (s *SessionManager) SyncSessionRound(r *http.Request, data *SessionData) {
+	s.loadSessionVotes(r, data, false)
+}
+
+// ReloadSessionVotes caches the open round's votes afresh, following any
+// device link, for when the identity behind the session has changed
+func (s *SessionManager) ReloadSessionVotes(r *http.Request, data *SessionData) {
+	s.loadSessionVotes(r, data, true)
+}
+
+// loadSessionVotes caches the votes for the open round in the session,
+// leaving them alone if they already belong to it unless forced. An identity
+// merge or undo since they were cached forces a reload too, and if it took
+// the session's name off this device the session takes up the device's
+// current name.
+func (s *SessionManager) loadSessionVotes(r *http.Request, data *SessionData, force bool) {
+	if DB == nil || data.UserName == "" {
+		return
+	}
+
+	generation, err := DB.GetMergeGeneration()
+	if err != nil {
+		LogErrorf("Error checking for identity merges: %v", err)
+		return
+	}
+	if generation != data.MergeGeneration {
+		force = true
+		if err := adoptMergedName(data); err != nil {
+			LogErrorf("Error looking up the merged name for %s: %v", data.DeviceID, err)
+			return
+		}
+	}
+
+	round, err := DB.GetCurrentRound()
+	if err != nil {
+		LogErrorf("Error fetching current round: %v", err)
+		return
+	}
+
+	var roundID uint
+	if round != nil {
+		roundID = round.ID
+	}
+	if roundID == data.RoundID && !force {
+		return
+	}
+
+	linkedDeviceID, err := DB.GetLinkedDevice(data.DeviceID)
+	if err != nil {
+		LogErrorf("Error looking up device link for %s: %v", data.DeviceID, err)
+		return
+	}
+	data.LinkedDeviceID = ""
+	if linkedDeviceID != data.DeviceID {
+		data.LinkedDeviceID = linkedDeviceID
+	}
+
+	votes, err := DB.GetUserVotes(data.UserName, data.VoterDeviceID(), roundID)
+	if err != nil {
+		LogErrorf("Error loading votes for round %d: %v", roundID, err)
+		return
+	}
+
+	data.RoundID = roundID
+	data.MergeGeneration = generation
+	data.Votes = make(map[int]types.Vote)
+	for _, vote := range votes {
+		data.Votes[vote.MovieID] = vote
+	}
+	s.PutSessionData(r, data)
+}
+
+// adoptMergedName switches the session to the device's current name when its
+// own is no longer among the device's names, as after a merge renamed it
+func adoptMergedName(data *SessionData) error {
+	names, err := DB.GetDeviceNames(data.DeviceID)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if name == data.UserName {
+			return nil
+		}
+	}
+
+	current, err := DB.GetDeviceMostRecentName(data.DeviceID)
+	if err != nil || current == "" {
+		return err
+	}
+	data.UserName = current
+	return nil
+}
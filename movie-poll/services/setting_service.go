@@ -0,0 +1,39 @@
+package services
+
+import (
+	"github.com/thornzero/movie-poll/models"
+	"gorm.io/gorm"
+)
+
+// Setting keys
+const (
+	SettingAppealStrategy  = "appeal_strategy"
+	SettingMergeGeneration = "merge_generation" // changes with every identity merge and undo
+)
+
+type SettingService struct {
+	db *gorm.DB
+}
+
+func NewSettingService(db *gorm.DB) *SettingService {
+	return &SettingService{db: db}
+}
+
+// GetSetting returns a stored setting, or fallback when it has never been set
+func (s *SettingService) GetSetting(key, fallback string) (string, error) {
+	var setting models.Setting
+	err := s.db.First(&setting, "key = ?", key).Error
+	if err == gorm.ErrRecordNotFound {
+		return fallback, nil
+	}
+	if err != nil {
+		return fallback, err
+	}
+	return setting.Value, nil
+}
+
+// SetSetting stores a setting, replacing any previous value
+func (s *SettingService) SetSetting(key, value string) error {
+	setting := models.Setting{Key: key, Value: value}
+	return s.db.Save(&setting).Error
+}
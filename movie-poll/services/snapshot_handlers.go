@@ -0,0 +1,79 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/thornzero/movie-poll/views"
+	"gorm.io/gorm"
+)
+
+// moversShown is how many movers the results page lists
+const moversShown = 5
+
+// handleAdminTakeAppealSnapshot snapshots the current rankings on demand
+func (hr *HandlerRegistry) handleAdminTakeAppealSnapshot(w http.ResponseWriter, r *http.Request) {
+	// Check if user is logged in as admin
+	sessionData := Session.GetSessionData(r)
+	if sessionData.AdminUser == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	snapshot, err := takeAppealSnapshot(SnapshotReasonManual)
+	if err != nil {
+		LogErrorf("Error taking appeal snapshot: %v", err)
+		http.Error(w, "Failed to take snapshot", http.StatusInternalServerError)
+		return
+	}
+	LogInfof("Admin %s took an appeal snapshot", sessionData.AdminUser.Username)
+
+	if r.Header.Get("HX-Request") != "true" {
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	views.AppealSnapshotStatus(len(snapshot.Entries), snapshot.TakenAt).Render(r.Context(), w)
+}
+
+// handleAppealTrend shows how a movie's rank has moved across snapshots. It
+// honours ?round= like the results page.
+func (hr *HandlerRegistry) handleAppealTrend(w http.ResponseWriter, r *http.Request) {
+	movieID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || movieID <= 0 {
+		http.Error(w, "Invalid movie ID", http.StatusBadRequest)
+		return
+	}
+
+	round, err := resolveResultsRound(r)
+	if err != nil {
+		LogErrorf("Error resolving results round: %v", err)
+		http.Error(w, "Round not found", http.StatusNotFound)
+		return
+	}
+	var roundID uint
+	roundName := ""
+	if round != nil {
+		roundID = round.ID
+		roundName = round.Name
+	}
+
+	trend, err := DB.GetAppealTrend(uint(movieID), roundID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "Movie not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		LogErrorf("Error loading appeal trend: %v", err)
+		http.Error(w, "Failed to load trend", http.StatusInternalServerError)
+		return
+	}
+
+	views.AppealTrendPage(views.AppealTrendData{
+		Trend:     *trend,
+		RoundName: roundName,
+		Selection: resultsSelection(r),
+	}).Render(r.Context(), w)
+}
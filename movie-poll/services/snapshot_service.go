@@ -0,0 +1,201 @@
+package services
+
+import (
+	"time"
+
+	"github.com/thornzero/movie-poll/models"
+	"github.com/thornzero/movie-poll/types"
+	"gorm.io/gorm"
+)
+
+// Why an appeal snapshot was taken
+const (
+	SnapshotReasonScheduled = "scheduled"
+	SnapshotReasonManual    = "manual"
+)
+
+type SnapshotService struct {
+	db *gorm.DB
+}
+
+func NewSnapshotService(db *gorm.DB) *SnapshotService {
+	return &SnapshotService{db: db}
+}
+
+// TakeSnapshot records every round's current appeal rankings under the
+// active strategy
+func (s *SnapshotService) TakeSnapshot(reason string) (*models.AppealSnapshot, error) {
+	var snapshot models.AppealSnapshot
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		strategy := activeAppealStrategy(tx)
+
+		var appeals []models.Appeal
+		err := tx.Where("strategy = ?", strategy.Name()).Scopes(scopeListedMovies).
+			Order("round_id, appeal_score DESC").
+			Find(&appeals).Error
+		if err != nil {
+			return err
+		}
+
+		snapshot = models.AppealSnapshot{
+			Strategy: strategy.Name(),
+			Reason:   reason,
+			TakenAt:  time.Now(),
+		}
+		rank := 0
+		for i, appeal := range appeals {
+			if i == 0 || derefRoundID(appeal.RoundID) != derefRoundID(appeals[i-1].RoundID) {
+				rank = 0
+			}
+			rank++
+			snapshot.Entries = append(snapshot.Entries, models.AppealSnapshotEntry{
+				MovieID:     appeal.MovieID,
+				RoundID:     appeal.RoundID,
+				Rank:        rank,
+				AppealScore: appeal.AppealScore,
+				TotalVotes:  appeal.TotalVotes,
+			})
+		}
+		return tx.Create(&snapshot).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// GetLatestSnapshotTime returns when the last snapshot was taken, or the
+// zero time if there are none
+func (s *SnapshotService) GetLatestSnapshotTime() (time.Time, error) {
+	var snapshot models.AppealSnapshot
+	err := s.db.Order("taken_at DESC").Limit(1).Find(&snapshot).Error
+	return snapshot.TakenAt, err
+}
+
+// PruneSnapshots deletes snapshots taken before the cutoff, returning how many
+// were removed
+func (s *SnapshotService) PruneSnapshots(before time.Time) (int64, error) {
+	var pruned int64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+		err := tx.Model(&models.AppealSnapshot{}).Where("taken_at < ?", before).Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		if err := tx.Where("snapshot_id IN ?", ids).Delete(&models.AppealSnapshotEntry{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.AppealSnapshot{}, ids)
+		pruned = result.RowsAffected
+		return result.Error
+	})
+	return pruned, err
+}
+
+// GetMovieTrend lists a movie's snapshot entries in a round for a strategy,
+// oldest first
+func (s *SnapshotService) GetMovieTrend(movieID, roundID uint, strategy string) ([]types.AppealTrendPoint, error) {
+	var rows []struct {
+		models.AppealSnapshotEntry
+		TakenAt time.Time
+	}
+	err := s.db.Model(&models.AppealSnapshotEntry{}).
+		Select("appeal_snapshot_entries.*, appeal_snapshots.taken_at").
+		Joins("JOIN appeal_snapshots ON appeal_snapshots.id = appeal_snapshot_entries.snapshot_id").
+		Where("appeal_snapshot_entries.movie_id = ? AND appeal_snapshots.strategy = ?", movieID, strategy).
+		Scopes(scopeSnapshotRound(roundID)).
+		Order("appeal_snapshots.taken_at").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]types.AppealTrendPoint, len(rows))
+	for i, row := range rows {
+		points[i] = types.AppealTrendPoint{
+			TakenAt:     row.TakenAt.Unix(),
+			Rank:        row.Rank,
+			AppealScore: row.AppealScore,
+			TotalVotes:  row.TotalVotes,
+		}
+	}
+	return points, nil
+}
+
+// GetLatestRankings returns the most recent snapshot for a strategy with
+// its entries for one round, or nil if there is none
+func (s *SnapshotService) GetLatestRankings(roundID uint, strategy string) (*models.AppealSnapshot, error) {
+	var snapshots []models.AppealSnapshot
+	err := s.db.Where("strategy = ?", strategy).
+		Order("taken_at DESC").Limit(1).
+		Preload("Entries", scopeSnapshotRound(roundID)).
+		Find(&snapshots).Error
+	if err != nil || len(snapshots) == 0 {
+		return nil, err
+	}
+	return &snapshots[0], nil
+}
+
+// scopeSnapshotRound limits snapshot entries to a round, with 0 meaning
+// votes cast outside any round
+func scopeSnapshotRound(roundID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if roundID == 0 {
+			return db.Where("appeal_snapshot_entries.round_id IS NULL")
+		}
+		return db.Where("appeal_snapshot_entries.round_id = ?", roundID)
+	}
+}
+
+// StartAppealSnapshots takes a snapshot every APPEAL_SNAPSHOT_HOURS in the
+// background, pruning ones older than APPEAL_SNAPSHOT_KEEP_DAYS. A restart
+// picks up where the schedule left off rather than snapshotting straight away.
+func StartAppealSnapshots() {
+	if Config.AppealSnapshotHours <= 0 {
+		return
+	}
+	interval := time.Duration(Config.AppealSnapshotHours) * time.Hour
+
+	go func() {
+		wait := interval
+		last, err := DB.GetLatestAppealSnapshotTime()
+		if err != nil {
+			LogErrorf("Error reading last appeal snapshot: %v", err)
+		} else if !last.IsZero() {
+			wait = time.Until(last.Add(interval))
+		}
+		if wait < 0 {
+			wait = 0
+		}
+
+		timer := time.NewTimer(wait)
+		for range timer.C {
+			if snapshot, err := takeAppealSnapshot(SnapshotReasonScheduled); err != nil {
+				LogErrorf("Error taking appeal snapshot: %v", err)
+			} else {
+				LogInfof("Took appeal snapshot of %d movies", len(snapshot.Entries))
+			}
+			timer.Reset(interval)
+		}
+	}()
+}
+
+// takeAppealSnapshot snapshots the rankings and applies the retention policy
+func takeAppealSnapshot(reason string) (*models.AppealSnapshot, error) {
+	snapshot, err := DB.TakeAppealSnapshot(reason)
+	if err != nil {
+		return nil, err
+	}
+
+	if Config.AppealSnapshotKeepDays > 0 {
+		cutoff := time.Now().AddDate(0, 0, -Config.AppealSnapshotKeepDays)
+		pruned, err := DB.PruneAppealSnapshots(cutoff)
+		if err != nil {
+			// The snapshot itself was kept
+			LogErrorf("Error pruning appeal snapshots: %v", err)
+		} else if pruned > 0 {
+			LogInfof("Pruned %d old appeal snapshots", pruned)
+		}
+	}
+	return snapshot, nil
+}
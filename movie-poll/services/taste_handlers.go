@@ -0,0 +1,77 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/thornzero/movie-poll/types"
+	"github.com/thornzero/movie-poll/views"
+)
+
+// handleAdminTaste shows the taste-similarity heatmap
+func (hr *HandlerRegistry) handleAdminTaste(w http.ResponseWriter, r *http.Request) {
+	// Check if user is logged in as admin
+	sessionData := Session.GetSessionData(r)
+	if sessionData.AdminUser == nil {
+		http.Redirect(w, r, "/admin", http.StatusSeeOther)
+		return
+	}
+
+	matrix, err := DB.GetTasteMatrix()
+	if err != nil {
+		LogErrorf("Error building taste matrix: %v", err)
+		http.Error(w, "Failed to load taste matrix", http.StatusInternalServerError)
+		return
+	}
+
+	views.AdminTastePage(*matrix).Render(r.Context(), w)
+}
+
+// handleAdminTasteAPI returns the taste-similarity matrix as JSON
+func (hr *HandlerRegistry) handleAdminTasteAPI(w http.ResponseWriter, r *http.Request) {
+	// Check if user is logged in as admin
+	sessionData := Session.GetSessionData(r)
+	if sessionData.AdminUser == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	matrix, err := DB.GetTasteMatrix()
+	if err != nil {
+		LogErrorf("Error building taste matrix: %v", err)
+		http.Error(w, "Failed to load taste matrix", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(matrix)
+}
+
+// finishedVotingTwin returns the session user's closest taste twin once
+// they've voted on every movie in the poll, and nil before then
+func finishedVotingTwin(r *http.Request) *types.TasteTwin {
+	sessionData := Session.GetSessionData(r)
+	if sessionData.UserName == "" {
+		return nil
+	}
+
+	sequence, err := getVotingSequence(sessionData)
+	if errors.Is(err, ErrNoOpenRound) {
+		return nil
+	}
+	if err != nil {
+		LogErrorf("Error loading voting sequence: %v", err)
+		return nil
+	}
+	if len(sequence) == 0 || nextUnvotedIndex(sequence, sessionData.Votes, -1) >= 0 {
+		return nil
+	}
+
+	twin, err := DB.GetTasteTwin(sessionData.UserName, sessionData.VoterDeviceID())
+	if err != nil {
+		LogErrorf("Error finding taste twin: %v", err)
+		return nil
+	}
+	return twin
+}
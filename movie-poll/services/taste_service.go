@@ -0,0 +1,187 @@
+package services
+
+import (
+	"math"
+	"sort"
+
+	"github.com/thornzero/movie-poll/models"
+	"github.com/thornzero/movie-poll/types"
+	"gorm.io/gorm"
+)
+
+type TasteService struct {
+	db *gorm.DB
+}
+
+func NewTasteService(db *gorm.DB) *TasteService {
+	return &TasteService{db: db}
+}
+
+// GetTasteMatrix compares every pair of voters over the movies they have
+// both rated. Agreement is one minus the average gap in enthusiasm, so it
+// stays defined for voters who give everything the same vibe. Pairs sharing
+// fewer than minShared movies are left out.
+func (s *TasteService) GetTasteMatrix(minShared int) (*types.TasteMatrix, error) {
+	users, votes, err := s.latestVotes()
+	if err != nil {
+		return nil, err
+	}
+	return buildTasteMatrix(users, votes, minShared), nil
+}
+
+// buildTasteMatrix compares the voters' latest votes pair by pair
+func buildTasteMatrix(users []types.TasteUser, votes []map[uint]models.Vote, minShared int) *types.TasteMatrix {
+	matrix := &types.TasteMatrix{
+		Users:     users,
+		Agreement: make([][]*float64, len(users)),
+		Shared:    make([][]int, len(users)),
+		MinShared: minShared,
+	}
+	for i := range users {
+		matrix.Agreement[i] = make([]*float64, len(users))
+		matrix.Shared[i] = make([]int, len(users))
+	}
+
+	for i := range users {
+		for j := i + 1; j < len(users); j++ {
+			shared, gap := 0, 0.0
+			for movieID, vote := range votes[i] {
+				other, ok := votes[j][movieID]
+				if !ok {
+					continue
+				}
+				shared++
+				gap += math.Abs(vibeEnthusiasm(vote.Vibe) - vibeEnthusiasm(other.Vibe))
+			}
+			matrix.Shared[i][j], matrix.Shared[j][i] = shared, shared
+			if shared == 0 || shared < minShared {
+				continue
+			}
+			agreement := 1 - gap/float64(shared)
+			matrix.Agreement[i][j], matrix.Agreement[j][i] = &agreement, &agreement
+		}
+	}
+	return matrix
+}
+
+// GetTasteTwin finds the voter who agrees most with the given one, breaking
+// ties by the number of shared movies. It returns nil if nobody shares
+// enough movies with them.
+func (s *TasteService) GetTasteTwin(userName, deviceID string, minShared int) (*types.TasteTwin, error) {
+	matrix, err := s.GetTasteMatrix(minShared)
+	if err != nil {
+		return nil, err
+	}
+
+	row := findTasteUser(matrix.Users, userName, deviceID)
+	if row < 0 {
+		return nil, nil
+	}
+
+	var twin *types.TasteTwin
+	for j, agreement := range matrix.Agreement[row] {
+		if agreement == nil {
+			continue
+		}
+		shared := matrix.Shared[row][j]
+		if twin == nil || *agreement > twin.Agreement ||
+			(*agreement == twin.Agreement && shared > twin.Shared) {
+			twin = &types.TasteTwin{
+				UserName:  matrix.Users[j].UserName,
+				Agreement: *agreement,
+				Shared:    shared,
+			}
+		}
+	}
+	return twin, nil
+}
+
+// PredictVibes guesses how a voter would rate each movie they haven't seen,
+// from the real ratings of up to k voters with the most similar taste who
+// have seen it. Neighbours are weighted by how much they agree with the
+// voter. Movies nobody similar has seen are left out.
+func (s *TasteService) PredictVibes(userName, deviceID string, minShared, k int) (map[uint]types.VibePrediction, error) {
+	users, votes, err := s.latestVotes()
+	if err != nil {
+		return nil, err
+	}
+	predictions := make(map[uint]types.VibePrediction)
+	row := findTasteUser(users, userName, deviceID)
+	if row < 0 {
+		return predictions, nil
+	}
+	matrix := buildTasteMatrix(users, votes, minShared)
+
+	// Most similar voters first
+	var neighbours []int
+	for j, agreement := range matrix.Agreement[row] {
+		if agreement != nil && *agreement > 0 {
+			neighbours = append(neighbours, j)
+		}
+	}
+	sort.SliceStable(neighbours, func(a, b int) bool {
+		return *matrix.Agreement[row][neighbours[a]] > *matrix.Agreement[row][neighbours[b]]
+	})
+
+	ratings := make(map[uint][]int) // movie -> neighbours who have seen it
+	for _, j := range neighbours {
+		for movieID, vote := range votes[j] {
+			if own, ok := votes[row][movieID]; ok && own.Seen {
+				continue
+			}
+			if vote.Seen && len(ratings[movieID]) < k {
+				ratings[movieID] = append(ratings[movieID], j)
+			}
+		}
+	}
+
+	for movieID, raters := range ratings {
+		total, weight := 0.0, 0.0
+		for _, j := range raters {
+			agreement := *matrix.Agreement[row][j]
+			total += agreement * vibeEnthusiasm(votes[j][movieID].Vibe)
+			weight += agreement
+		}
+		predictions[movieID] = types.VibePrediction{
+			MovieID:    movieID,
+			Enthusiasm: total / weight,
+			Neighbours: len(raters),
+		}
+	}
+	return predictions, nil
+}
+
+// latestVotes returns every voter, sorted by name, with their most recent
+// vote on each movie across all rounds
+func (s *TasteService) latestVotes() ([]types.TasteUser, []map[uint]models.Vote, error) {
+	var rows []models.Vote
+	err := s.db.Scopes(scopeListedMovies).
+		Order("user_name, device_id, updated_at, id").
+		Find(&rows).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	users := []types.TasteUser{}
+	votes := []map[uint]models.Vote{}
+	for _, vote := range rows {
+		last := len(users) - 1
+		if last < 0 || users[last].UserName != vote.UserName || users[last].DeviceID != vote.DeviceID {
+			users = append(users, types.TasteUser{UserName: vote.UserName, DeviceID: vote.DeviceID})
+			votes = append(votes, make(map[uint]models.Vote))
+			last++
+		}
+		votes[last][vote.MovieID] = vote
+	}
+	return users, votes, nil
+}
+
+// findTasteUser returns a voter's position in users, or -1
+func findTasteUser(users []types.TasteUser, userName, deviceID string) int {
+	for i, user := range users {
+		if user.UserName == userName && user.DeviceID == deviceID {
+			return i
+		}
+	}
+	return -1
+}
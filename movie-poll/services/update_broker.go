@@ -0,0 +1,97 @@
+package services
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	ErrTooManyListeners = errors.New("too many live listeners")
+	ErrBrokerClosed     = errors.New("live updates are shutting down")
+)
+
+const (
+	// maxLiveListeners caps open streams per broker
+	maxLiveListeners = 25
+
+	// maxLiveStreams is the router's throttle for event streams, which are
+	// kept out of the one other requests share. A stream listens to at least
+	// one of the two brokers, so the brokers fill up before it does.
+	maxLiveStreams = 2 * maxLiveListeners
+)
+
+// UpdateBroker tells every open event stream when something it shows changes.
+// Each listener has a one-slot mailbox: publishing never waits, and a listener
+// that's still busy with the last update just picks up one more, so a slow
+// phone only ever delays itself.
+type UpdateBroker struct {
+	mu        sync.Mutex
+	listeners map[chan struct{}]struct{}
+	closed    bool
+}
+
+func NewUpdateBroker() *UpdateBroker {
+	return &UpdateBroker{listeners: make(map[chan struct{}]struct{})}
+}
+
+// Subscribe registers a listener, returning its channel and a function to
+// unregister it. The channel is closed when the listener unregisters or the
+// broker closes.
+func (b *UpdateBroker) Subscribe() (<-chan struct{}, func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, nil, ErrBrokerClosed
+	}
+	if len(b.listeners) >= maxLiveListeners {
+		return nil, nil, ErrTooManyListeners
+	}
+	ch := make(chan struct{}, 1)
+	b.listeners[ch] = struct{}{}
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.listeners[ch]; ok {
+			delete(b.listeners, ch)
+			close(ch)
+		}
+	}
+	return ch, unsubscribe, nil
+}
+
+// Publish wakes every listener without blocking
+func (b *UpdateBroker) Publish() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.listeners {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// Already has an update waiting
+		}
+	}
+}
+
+// Close ends every listener's channel so open streams finish and the server
+// can shut down
+func (b *UpdateBroker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for ch := range b.listeners {
+		close(ch)
+		delete(b.listeners, ch)
+	}
+}
+
+// notifyResultsChanged tells open results pages to refresh. It's a no-op
+// outside the web server.
+func notifyResultsChanged() {
+	if LiveResults != nil {
+		LiveResults.Publish()
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/thornzero/movie-poll/models"
 	"github.com/thornzero/movie-poll/views"
 )
 
@@ -33,10 +34,18 @@ func (hr *HandlerRegistry) handleAdminUsers(w http.ResponseWriter, r *http.Reque
 		userStats = make(map[string]interface{})
 	}
 
+	// Get the devices behind each name
+	devices, err := DB.GetDevicesByName()
+	if err != nil {
+		LogErrorf("Error getting devices: %v", err)
+		devices = make(map[string][]models.Device)
+	}
+
 	// Create users data
 	usersData := views.AdminUsersData{
 		Users:     users,
 		UserStats: userStats,
+		Devices:   devices,
 	}
 
 	// Render the users page
@@ -139,15 +139,27 @@ func (s *UserService) UpdateUserStats(userName, deviceID string) error {
 
 // DeleteUser deletes a user and their votes
 func (s *UserService) DeleteUser(userName, deviceID string) error {
-	// Delete user's votes first
-	err := s.db.Where("user_name = ? AND device_id = ?", userName, deviceID).Delete(&models.Vote{}).Error
-	if err != nil {
-		return err
-	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// Delete user's votes first, keeping a record in the history
+		var votes []models.Vote
+		err := tx.Where("user_name = ? AND device_id = ?", userName, deviceID).Find(&votes).Error
+		if err != nil {
+			return err
+		}
+		if err := recordVoteDeletions(tx, votes, RevisionSourceDeleteUser); err != nil {
+			return err
+		}
+		err = tx.Where("user_name = ? AND device_id = ?", userName, deviceID).Delete(&models.Vote{}).Error
+		if err != nil {
+			return err
+		}
+		if err := refreshVoteAppeals(tx, votes); err != nil {
+			return err
+		}
 
-	// Delete user
-	err = s.db.Where("user_name = ? AND device_id = ?", userName, deviceID).Delete(&models.User{}).Error
-	return err
+		// Delete user
+		return tx.Where("user_name = ? AND device_id = ?", userName, deviceID).Delete(&models.User{}).Error
+	})
 }
 
 // GetUsersWithVotes returns users who have voted
@@ -46,33 +46,3 @@ func VerifyPassword(password, encodedHash string) (bool, error) {
 
 	return subtle.ConstantTimeCompare(hash, expectedHash) == 1, nil
 }
-
-// calculateNameSimilarity calculates similarity between two names
-func calculateNameSimilarity(name1, name2 string) float64 {
-	// Simple similarity calculation based on common characters
-	name1 = strings.ToLower(strings.TrimSpace(name1))
-	name2 = strings.ToLower(strings.TrimSpace(name2))
-
-	if name1 == name2 {
-		return 1.0
-	}
-
-	// Count common characters
-	common := 0
-	minLen := len(name1)
-	if len(name2) < minLen {
-		minLen = len(name2)
-	}
-
-	for i := 0; i < minLen; i++ {
-		if name1[i] == name2[i] {
-			common++
-		}
-	}
-
-	if minLen == 0 {
-		return 0.0
-	}
-
-	return float64(common) / float64(minLen)
-}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/thornzero/movie-poll/views"
+)
+
+// handleVotingVeto handles spending a veto on a movie
+func (hr *HandlerRegistry) handleVotingVeto(w http.ResponseWriter, r *http.Request) {
+	hr.updateVeto(w, r, func(sessionData *SessionData, movieID uint) error {
+		return DB.CastVeto(sessionData.UserName, sessionData.VoterDeviceID(), movieID, Config.VetoesPerUser)
+	})
+}
+
+// handleVotingWithdrawVeto handles taking back a spent veto
+func (hr *HandlerRegistry) handleVotingWithdrawVeto(w http.ResponseWriter, r *http.Request) {
+	hr.updateVeto(w, r, func(sessionData *SessionData, movieID uint) error {
+		return DB.WithdrawVeto(sessionData.UserName, sessionData.VoterDeviceID(), movieID)
+	})
+}
+
+// updateVeto applies a veto action to the movie in the form and re-renders
+// the movie's veto control
+func (hr *HandlerRegistry) updateVeto(w http.ResponseWriter, r *http.Request, action func(sessionData *SessionData, movieID uint) error) {
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	movieID, err := strconv.Atoi(r.FormValue("movie_id"))
+	if err != nil || movieID <= 0 {
+		http.Error(w, "Invalid movie ID", http.StatusBadRequest)
+		return
+	}
+
+	// Get session data
+	sessionData := Session.GetSessionData(r)
+	if sessionData.UserName == "" {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	err = action(sessionData, uint(movieID))
+	if errors.Is(err, ErrNoVetoesLeft) || errors.Is(err, ErrAlreadyVetoed) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		writeVoteError(w, err)
+		return
+	}
+
+	vetoed, remaining := getVetoState(sessionData)
+	views.VetoControl(movieID, views.VetoStatus{Vetoed: vetoed[movieID], Remaining: remaining}).Render(r.Context(), w)
+}
+
+// getVetoState returns the movies the session's user has vetoed in the
+// current round and how many vetoes they have left
+func getVetoState(sessionData *SessionData) (map[int]bool, int) {
+	vetoed := make(map[int]bool)
+	movieIDs, err := DB.GetUserVetoes(sessionData.UserName, sessionData.VoterDeviceID(), sessionData.RoundID)
+	if err != nil {
+		LogErrorf("Error loading vetoes for %s: %v", sessionData.UserName, err)
+		return vetoed, 0
+	}
+
+	for _, movieID := range movieIDs {
+		vetoed[int(movieID)] = true
+	}
+	remaining := Config.VetoesPerUser - len(movieIDs)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return vetoed, remaining
+}
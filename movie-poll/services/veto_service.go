@@ -0,0 +1,177 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/thornzero/movie-poll/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNoVetoesLeft  = errors.New("no vetoes left")
+	ErrAlreadyVetoed = errors.New("movie already vetoed")
+)
+
+type VetoService struct {
+	db *gorm.DB
+}
+
+func NewVetoService(db *gorm.DB) *VetoService {
+	return &VetoService{db: db}
+}
+
+// CastVeto spends one of the participant's vetoes on a movie in the current
+// round. allowance is the number of vetoes each participant gets per round.
+func (s *VetoService) CastVeto(userName, deviceID string, movieID uint, allowance int) error {
+	// A cast that loses a race for a slot tries again against the vetoes the
+	// winner left, so it either takes another free slot or runs out
+	for attempt := 0; ; attempt++ {
+		err := s.castVeto(userName, deviceID, movieID, allowance)
+		if !errors.Is(err, gorm.ErrDuplicatedKey) || attempt >= allowance {
+			return err
+		}
+	}
+}
+
+func (s *VetoService) castVeto(userName, deviceID string, movieID uint, allowance int) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		roundID, err := NewRoundService(tx).ResolveVoteRound(0, movieID)
+		if err != nil {
+			return err
+		}
+
+		var vetoes []models.Veto
+		err = tx.Where("user_name = ? AND device_id = ?", userName, deviceID).
+			Scopes(scopeRound(derefRoundID(roundID))).
+			Find(&vetoes).Error
+		if err != nil {
+			return err
+		}
+
+		for _, veto := range vetoes {
+			if veto.MovieID == movieID {
+				return ErrAlreadyVetoed
+			}
+		}
+		if len(vetoes) >= allowance {
+			return ErrNoVetoesLeft
+		}
+
+		err = tx.Create(&models.Veto{
+			MovieID:  movieID,
+			UserName: userName,
+			DeviceID: deviceID,
+			RoundID:  roundID,
+			Slot:     freeVetoSlot(vetoes),
+		}).Error
+		if err != nil {
+			return err
+		}
+		return refreshMovieAppeal(tx, movieID, derefRoundID(roundID))
+	})
+}
+
+// freeVetoSlot returns the lowest slot none of a participant's vetoes holds.
+// While they have fewer vetoes than their allowance it is within it.
+func freeVetoSlot(vetoes []models.Veto) int {
+	taken := make(map[int]bool, len(vetoes))
+	for _, veto := range vetoes {
+		taken[veto.Slot] = true
+	}
+	slot := 1
+	for taken[slot] {
+		slot++
+	}
+	return slot
+}
+
+// WithdrawVeto gives a spent veto back to the participant
+func (s *VetoService) WithdrawVeto(userName, deviceID string, movieID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		roundID, err := NewRoundService(tx).ResolveVoteRound(0, movieID)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("movie_id = ? AND user_name = ? AND device_id = ?", movieID, userName, deviceID).
+			Scopes(scopeRound(derefRoundID(roundID))).
+			Delete(&models.Veto{}).Error
+		if err != nil {
+			return err
+		}
+		return refreshMovieAppeal(tx, movieID, derefRoundID(roundID))
+	})
+}
+
+// GetUserVetoes returns the IDs of the movies a participant vetoed in a round
+func (s *VetoService) GetUserVetoes(userName, deviceID string, roundID uint) ([]uint, error) {
+	var movieIDs []uint
+	err := s.db.Model(&models.Veto{}).
+		Where("user_name = ? AND device_id = ?", userName, deviceID).
+		Scopes(scopeRound(roundID)).
+		Pluck("movie_id", &movieIDs).Error
+	return movieIDs, err
+}
+
+// GetVetoCounts returns the number of vetoes each movie received in a round
+func (s *VetoService) GetVetoCounts(roundID uint) (map[uint]int, error) {
+	var rows []struct {
+		MovieID uint
+		Count   int
+	}
+	err := s.db.Model(&models.Veto{}).
+		Select("movie_id, COUNT(*) as count").
+		Scopes(scopeRound(roundID)).
+		Group("movie_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		counts[row.MovieID] = row.Count
+	}
+	return counts, nil
+}
+
+// DeleteAllVetoes hands every participant their full allowance back
+func (s *VetoService) DeleteAllVetoes() error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.Veto{}).Error; err != nil {
+			return err
+		}
+		return rebuildAllAppeals(tx)
+	})
+}
+
+// migrateVetoSlots adds the slot column to a veto table from before it
+// existed and numbers each participant's vetoes per round in the order they
+// were cast, so the slot index can be built over them.
+func migrateVetoSlots(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&models.Veto{}) || migrator.HasColumn(&models.Veto{}, "Slot") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Migrator().AddColumn(&models.Veto{}, "Slot"); err != nil {
+			return err
+		}
+
+		var vetoes []models.Veto
+		if err := tx.Order("created_at, id").Find(&vetoes).Error; err != nil {
+			return err
+		}
+		slots := make(map[string]int)
+		for _, veto := range vetoes {
+			key := fmt.Sprintf("%s\x00%s\x00%d", veto.UserName, veto.DeviceID, derefRoundID(veto.RoundID))
+			slots[key]++
+			if err := tx.Model(&veto).UpdateColumn("slot", slots[key]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -1,11 +1,15 @@
 package services
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 
 	"github.com/a-h/templ"
+	"github.com/thornzero/movie-poll/models"
 	"github.com/thornzero/movie-poll/types"
 	"github.com/thornzero/movie-poll/views"
 )
@@ -21,8 +25,15 @@ func (hr *HandlerRegistry) handleHome(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get movies from database
-	movies, err := DB.GetMovies(Config.MovieLimit)
+	// Make sure cached votes belong to the open round
+	Session.SyncSessionRound(r, sessionData)
+
+	// Get movies in this user's voting order
+	movies, err := getVotingSequence(sessionData)
+	if errors.Is(err, ErrNoOpenRound) {
+		views.NoOpenRoundPage(sessionData.UserName).Render(r.Context(), w)
+		return
+	}
 	if err != nil {
 		LogErrorf("Error fetching movies: %v", err)
 		http.Error(w, "Failed to load movies", http.StatusInternalServerError)
@@ -30,41 +41,101 @@ func (hr *HandlerRegistry) handleHome(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Render the movie poll page
-	renderMoviePollPage(w, r, movies, sessionData)
+	renderMoviePollPage(w, r, movies, sessionData, nextUnvotedIndex(movies, sessionData.Votes, -1))
 }
 
 func (hr *HandlerRegistry) handleResults(w http.ResponseWriter, r *http.Request) {
-	// Calculate appeal scores first
-	err := DB.CalculateAppealScores()
+	// Work out which round and event to show
+	options, ok := resolveResultsOptions(w, r)
+	if !ok {
+		return
+	}
+
+	// Sort and filter like the results API, but on one page
+	query, err := parseResultsQuery(r)
 	if err != nil {
-		LogErrorf("Error calculating appeal scores: %v", err)
-		// Continue anyway - we'll show what we have
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
+	query.PageSize = 0
 
-	// Get results data
-	votingSummary, err := DB.GetResultsSummary()
+	resultsData, err := buildResultsData(r, options, query)
+	if errors.Is(err, ErrInvalidResultsSort) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		LogErrorf("Error fetching results summary: %v", err)
 		http.Error(w, "Failed to load results", http.StatusInternalServerError)
 		return
 	}
 
+	resultsData.TasteTwin = finishedVotingTwin(r)
+
+	// Render the results page
+	views.ResultsPage(resultsData).Render(r.Context(), w)
+}
+
+// buildResultsData gathers everything the results page shows. Only failing to
+// load the results themselves is an error; the extras are left out instead.
+func buildResultsData(r *http.Request, options types.ResultsOptions, query types.ResultsQuery) (views.ResultsData, error) {
+	resultsData := views.ResultsData{
+		Strategy:     convertAppealStrategyToInfo(DB.GetActiveAppealStrategy(), true),
+		Sort:         query.Sort,
+		HideDivisive: query.MaxPolarization < 1,
+		Selection:    resultsSelection(r),
+	}
+
+	// Get results data
+	results, err := DB.GetResultsList(options, query)
+	if err != nil {
+		return resultsData, err
+	}
+	resultsData.Movies = results.Results
+
+	if options.RoundID > 0 {
+		if round, err := DB.GetRound(options.RoundID); err == nil {
+			resultsData.RoundName = round.Name
+		}
+	}
+	if options.EventID > 0 {
+		if event, err := DB.GetEvent(options.EventID); err == nil {
+			resultsData.EventName = event.Name
+		}
+	}
+
 	// Get voting statistics
-	stats, err := DB.GetVotingStats()
+	stats, err := DB.GetVotingStats(options.RoundID)
 	if err != nil {
 		LogErrorf("Error fetching voting stats: %v", err)
 		// Continue with empty stats
 		stats = &types.VotingStats{}
 	}
+	resultsData.Stats = *stats
 
-	// Create results data
-	resultsData := views.ResultsData{
-		Movies: votingSummary,
-		Stats:  *stats,
+	// Count ranked-choice ballots
+	runoff, err := DB.TallyInstantRunoff(options.RoundID)
+	if err != nil {
+		LogErrorf("Error tallying ranked ballots: %v", err)
+		// Continue without the runoff section
+		runoff = nil
 	}
+	resultsData.Runoff = runoff
 
-	// Render the results page
-	views.ResultsPage(resultsData).Render(r.Context(), w)
+	// Compare the group's ranking with the last snapshot
+	if options.EventID == 0 {
+		ranked, err := DB.GetResultsSummary(options)
+		if err == nil {
+			resultsData.Movers, err = DB.GetAppealMovers(options.RoundID, ranked, moversShown)
+		}
+		if err != nil {
+			LogErrorf("Error finding appeal movers: %v", err)
+			// Continue without the movers section
+			resultsData.Movers = nil
+		}
+	}
+
+	return resultsData, nil
 }
 
 func (hr *HandlerRegistry) handleVotingSeen(w http.ResponseWriter, r *http.Request) {
@@ -203,8 +274,12 @@ func (hr *HandlerRegistry) handleAdminDashboard(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	// Get admin stats
-	stats, err := DB.GetVotingStats()
+	// Get admin stats for the round results currently default to
+	var roundID uint
+	if round, err := DB.GetResultsRound(); err == nil && round != nil {
+		roundID = round.ID
+	}
+	stats, err := DB.GetVotingStats(roundID)
 	if err != nil {
 		LogErrorf("Error getting admin stats: %v", err)
 		stats = &types.VotingStats{}
@@ -248,6 +323,7 @@ func (hr *HandlerRegistry) handleAdminDashboard(w http.ResponseWriter, r *http.R
 		},
 		RecentMovies: adminMovies,
 		RecentVotes:  []views.VoteInfo{}, // TODO: Implement recent votes
+		Strategies:   convertAppealStrategiesToInfo(DB.GetActiveAppealStrategy()),
 	}
 
 	views.AdminDashboard(dashboardData).Render(r.Context(), w)
@@ -269,6 +345,20 @@ func (hr *HandlerRegistry) handleAdminMovies(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	screened, err := DB.GetScreeningDates()
+	if err != nil {
+		LogErrorf("Error getting screenings for admin: %v", err)
+		http.Error(w, "Failed to load movies", http.StatusInternalServerError)
+		return
+	}
+
+	nominations, err := DB.GetNominations(models.NominationPending)
+	if err != nil {
+		LogErrorf("Error getting nominations for admin: %v", err)
+		http.Error(w, "Failed to load nominations", http.StatusInternalServerError)
+		return
+	}
+
 	// Convert to admin format
 	adminMovies := make([]views.MovieInfo, len(movies))
 	for i, movie := range movies {
@@ -283,6 +373,9 @@ func (hr *HandlerRegistry) handleAdminMovies(w http.ResponseWriter, r *http.Requ
 			VoteCount: 0,          // TODO: Get actual vote count
 			AddedAt:   time.Now(), // TODO: Get actual added date
 		}
+		if screenedAt, ok := screened[uint(movie.ID)]; ok {
+			adminMovies[i].ScreenedAt = &screenedAt
+		}
 	}
 
 	// Create movies page data
@@ -291,7 +384,8 @@ func (hr *HandlerRegistry) handleAdminMovies(w http.ResponseWriter, r *http.Requ
 			ID:       sessionData.AdminUser.ID,
 			Username: sessionData.AdminUser.Username,
 		},
-		Movies: adminMovies,
+		Movies:      adminMovies,
+		Nominations: convertNominationsToInfo(nominations),
 	}
 
 	views.AdminMoviesPage(moviesData).Render(r.Context(), w)
@@ -303,12 +397,71 @@ func (hr *HandlerRegistry) handleDebugPage(w http.ResponseWriter, r *http.Reques
 
 // Helper functions for rendering
 
-func renderMoviePollPage(w http.ResponseWriter, r *http.Request, movies []types.Movie, sessionData *SessionData) {
+// resolveResultsRound returns the round named by the "round" query parameter,
+// falling back to the round results default to
+func resolveResultsRound(r *http.Request) (*models.PollRound, error) {
+	if roundStr := r.URL.Query().Get("round"); roundStr != "" {
+		roundID, err := strconv.Atoi(roundStr)
+		if err != nil || roundID <= 0 {
+			return nil, fmt.Errorf("invalid round ID %q", roundStr)
+		}
+		return DB.GetRound(uint(roundID))
+	}
+	return DB.GetResultsRound()
+}
+
+// resultsSelection keeps the ?round= and ?event= parameters for links that
+// re-sort or filter the results page
+func resultsSelection(r *http.Request) url.Values {
+	selection := url.Values{}
+	for _, key := range []string{"round", "event"} {
+		if value := r.URL.Query().Get(key); value != "" {
+			selection.Set(key, value)
+		}
+	}
+	return selection
+}
+
+// resolveResultsEvent returns the event named by the ?event= query parameter,
+// or nil when results should count everyone
+func resolveResultsEvent(r *http.Request) (*models.Event, error) {
+	eventStr := r.URL.Query().Get("event")
+	if eventStr == "" {
+		return nil, nil
+	}
+	eventID, err := strconv.Atoi(eventStr)
+	if err != nil || eventID <= 0 {
+		return nil, fmt.Errorf("invalid event ID %q", eventStr)
+	}
+	return DB.GetEvent(uint(eventID))
+}
+
+// renderMoviePollPage renders the voting page with movies in the order given,
+// opening on the movie at startSlide (or the first if it's negative)
+func renderMoviePollPage(w http.ResponseWriter, r *http.Request, movies []types.Movie, sessionData *SessionData, startSlide int) {
+	// Look up which movies the user has vetoed
+	vetoed, remaining := getVetoState(sessionData)
+
+	// Credit movies that came from the nomination queue
+	nominations, err := DB.GetMovieNominations()
+	if err != nil {
+		LogErrorf("Error getting movie nominations: %v", err)
+		// Continue without nominator credits
+	}
+
+	// Guess how the user will feel about movies they haven't seen
+	predictions, err := DB.PredictVibes(sessionData.UserName, sessionData.VoterDeviceID())
+	if err != nil {
+		LogErrorf("Error predicting vibes: %v", err)
+		// Continue without predictions
+	}
+
 	// Create movie card components
 	var components []templ.Component
 	for _, movie := range movies {
 		// Check if user has voted on this movie
 		userVote, hasVoted := sessionData.Votes[movie.ID]
+		veto := views.VetoStatus{Vetoed: vetoed[movie.ID], Remaining: remaining}
 
 		// Create movie card struct
 		year := movie.Year
@@ -324,22 +477,37 @@ func renderMoviePollPage(w http.ResponseWriter, r *http.Request, movies []types.
 			PosterPath:  posterPath,
 			ReleaseDate: releaseDate,
 		}
+		if nomination, ok := nominations[uint(movie.ID)]; ok {
+			movieCard.NominatedBy = nomination.UserName
+			movieCard.Pitch = nomination.Pitch
+		}
+		if prediction, ok := predictions[uint(movie.ID)]; ok {
+			movieCard.Prediction = &prediction
+		}
 
 		// Create the voting interface component
-		cardComponent := views.MovieCardTemplate(movieCard, hasVoted, userVote)
+		cardComponent := views.MovieCardTemplate(movieCard, hasVoted, userVote, veto)
 		components = append(components, cardComponent)
 	}
 
 	// Render the movie poll page
-	views.MoviePollLayout(components, sessionData.UserName, len(movies), len(sessionData.Votes)).Render(r.Context(), w)
+	if startSlide < 0 {
+		startSlide = 0
+	}
+	views.MoviePollLayout(components, sessionData.UserName, len(movies), countVoted(movies, sessionData.Votes), startSlide).Render(r.Context(), w)
 }
 
 func renderVotedStateWithAdvance(w http.ResponseWriter, r *http.Request, movieID int, vote types.Vote, sessionData *SessionData) {
 	// First render the voted state
 	views.VotedState(movieID, vote).Render(r.Context(), w)
 
+	// In a live vote the host decides when to move on
+	if r.Header.Get("X-Live-Vote") == "true" {
+		return
+	}
+
 	// Get all movies to check if all have been voted on
-	movies, err := DB.GetMovies(Config.MovieLimit)
+	movies, err := getVotingSequence(sessionData)
 	if err != nil {
 		LogErrorf("Error fetching movies for completion check: %v", err)
 		// Fallback to just advancing slide
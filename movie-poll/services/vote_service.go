@@ -1,11 +1,27 @@
 package services
 
 import (
+	"fmt"
+
 	"github.com/thornzero/movie-poll/models"
 	"github.com/thornzero/movie-poll/types"
 	"gorm.io/gorm"
 )
 
+// BatchVoteError identifies the vote that caused a batch to be rolled back
+type BatchVoteError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchVoteError) Error() string {
+	return fmt.Sprintf("vote %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchVoteError) Unwrap() error {
+	return e.Err
+}
+
 type VoteService struct {
 	db *gorm.DB
 }
@@ -14,19 +30,81 @@ func NewVoteService(db *gorm.DB) *VoteService {
 	return &VoteService{db: db}
 }
 
-// SubmitVote - replaces 30+ line SubmitVote function
-func (s *VoteService) SubmitVote(vote *types.Vote) error {
-	gormVote := convertTypeVoteToGORM(vote)
-	return s.db.Where("movie_id = ? AND user_name = ? AND device_id = ?",
-		gormVote.MovieID, gormVote.UserName, gormVote.DeviceID).
-		Assign(gormVote).
-		FirstOrCreate(&gormVote).Error
+// SubmitVote - replaces 30+ line SubmitVote function. source names the
+// endpoint the vote came through and is kept in the revision history.
+func (s *VoteService) SubmitVote(vote *types.Vote, source string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return s.upsertVote(tx, vote, source)
+	})
+}
+
+// SubmitVotes saves a batch of votes atomically: either every vote is
+// written or none are. A failure is reported as a *BatchVoteError naming the
+// vote that caused it.
+func (s *VoteService) SubmitVotes(votes []types.Vote, source string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for i := range votes {
+			if err := s.upsertVote(tx, &votes[i], source); err != nil {
+				return &BatchVoteError{Index: i, Err: err}
+			}
+		}
+		return nil
+	})
+}
+
+// upsertVote creates or updates the user's vote on a movie in its round and
+// records the change. Resubmitting an identical vote records nothing.
+func (s *VoteService) upsertVote(tx *gorm.DB, vote *types.Vote, source string) error {
+	roundID, err := NewRoundService(tx).ResolveVoteRound(uint(vote.RoundID), uint(vote.MovieID))
+	if err != nil {
+		return err
+	}
+	vote.RoundID = int(derefRoundID(roundID))
+
+	var existing models.Vote
+	err = tx.Where("movie_id = ? AND user_name = ? AND device_id = ?",
+		vote.MovieID, vote.UserName, vote.DeviceID).
+		Scopes(scopeRound(derefRoundID(roundID))).
+		First(&existing).Error
+	if err == gorm.ErrRecordNotFound {
+		gormVote := convertTypeVoteToGORM(vote)
+		gormVote.RoundID = roundID
+		if err := tx.Create(&gormVote).Error; err != nil {
+			return err
+		}
+		vote.ID = int(gormVote.ID)
+		if err := recordVoteRevision(tx, nil, &gormVote, source); err != nil {
+			return err
+		}
+		return refreshMovieAppeal(tx, gormVote.MovieID, derefRoundID(roundID))
+	}
+	if err != nil {
+		return err
+	}
+
+	vote.ID = int(existing.ID)
+	vote.CreatedAt = existing.CreatedAt.Unix()
+	if existing.Vibe == vote.Vibe && existing.Seen == vote.Seen {
+		return nil
+	}
+
+	before := existing
+	existing.Vibe = vote.Vibe
+	existing.Seen = vote.Seen
+	if err := tx.Save(&existing).Error; err != nil {
+		return err
+	}
+	if err := recordVoteRevision(tx, &before, &existing, source); err != nil {
+		return err
+	}
+	return refreshMovieAppeal(tx, existing.MovieID, derefRoundID(roundID))
 }
 
 // GetUserVotes - replaces 20+ line GetUserVotes function
-func (s *VoteService) GetUserVotes(userName, deviceID string) ([]types.Vote, error) {
+func (s *VoteService) GetUserVotes(userName, deviceID string, roundID uint) ([]types.Vote, error) {
 	var votes []models.Vote
 	err := s.db.Where("user_name = ? AND device_id = ?", userName, deviceID).
+		Scopes(scopeRound(roundID)).
 		Order("created_at DESC").
 		Find(&votes).Error
 	if err != nil {
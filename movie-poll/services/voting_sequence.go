@@ -0,0 +1,81 @@
+package services
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+
+	"github.com/thornzero/movie-poll/types"
+)
+
+// VotingSequence returns the movies in the order a participant should vote
+// on them. The order is a shuffle seeded from the participant's identity and
+// the set of movies on the slate, so it's stable across reloads and changes
+// whenever the slate does.
+func VotingSequence(movies []types.Movie, userName, deviceID string) []types.Movie {
+	sequence := make([]types.Movie, len(movies))
+	copy(sequence, movies)
+
+	// Start from a canonical order so the result doesn't depend on how the
+	// slate was sorted
+	sort.Slice(sequence, func(i, j int) bool {
+		return sequence[i].ID < sequence[j].ID
+	})
+
+	hash := fnv.New64a()
+	hash.Write([]byte(userName))
+	hash.Write([]byte{0})
+	hash.Write([]byte(deviceID))
+	for _, movie := range sequence {
+		hash.Write([]byte{0})
+		hash.Write([]byte(strconv.Itoa(movie.ID)))
+	}
+	seed := hash.Sum64()
+
+	rng := rand.New(rand.NewPCG(seed, seed>>32|seed<<32))
+	rng.Shuffle(len(sequence), func(i, j int) {
+		sequence[i], sequence[j] = sequence[j], sequence[i]
+	})
+	return sequence
+}
+
+// getVotingSequence loads the poll movies in the session user's voting order.
+// The whole slate is shuffled before MOVIE_LIMIT applies, so a large slate
+// gives each voter a different share of it rather than the same first few.
+func getVotingSequence(sessionData *SessionData) ([]types.Movie, error) {
+	movies, err := DB.GetPollMovies(0)
+	if err != nil {
+		return nil, err
+	}
+	sequence := VotingSequence(movies, sessionData.UserName, sessionData.VoterDeviceID())
+	if Config.MovieLimit > 0 && len(sequence) > Config.MovieLimit {
+		sequence = sequence[:Config.MovieLimit]
+	}
+	return sequence, nil
+}
+
+// nextUnvotedIndex returns the index of the first movie after position from
+// that the user hasn't voted on, wrapping around to the start of the
+// sequence. Pass -1 to search from the beginning. Returns -1 if every movie
+// has a vote.
+func nextUnvotedIndex(sequence []types.Movie, votes map[int]types.Vote, from int) int {
+	for offset := 1; offset <= len(sequence); offset++ {
+		i := (from + offset) % len(sequence)
+		if _, hasVoted := votes[sequence[i].ID]; !hasVoted {
+			return i
+		}
+	}
+	return -1
+}
+
+// countVoted returns how many movies in the sequence the user has voted on
+func countVoted(sequence []types.Movie, votes map[int]types.Vote) int {
+	voted := 0
+	for _, movie := range sequence {
+		if _, hasVoted := votes[movie.ID]; hasVoted {
+			voted++
+		}
+	}
+	return voted
+}
@@ -67,6 +67,7 @@ function initializeSwipers() {
           } : {
             slidesPerView: 1,
             spaceBetween: 20,
+            initialSlide: parseInt(swiperEl.dataset.initialSlide || '0', 10),
             centeredSlides: true,
             loop: false,
             pagination: {
@@ -0,0 +1,99 @@
+// Server-Sent Events extension for htmx 2, covering the part of the
+// htmx-ext-sse API the live pages use: sse-connect opens an EventSource on an
+// element, and each sse-swap element inside it (or on it) swaps in the events
+// it names. The swap style comes from hx-swap and defaults to innerHTML.
+(function() {
+  let api;
+
+  htmx.defineExtension('sse', {
+    init: function(apiRef) {
+      api = apiRef;
+    },
+
+    onEvent: function(name, evt) {
+      const elt = evt.target || evt.detail.elt;
+      switch (name) {
+        case 'htmx:beforeCleanupElement': {
+          const internal = api.getInternalData(elt);
+          if (internal.sseEventSource) {
+            internal.sseEventSource.close();
+            clearTimeout(internal.sseRetry);
+          }
+          return;
+        }
+        case 'htmx:afterProcessNode':
+          if (elt.hasAttribute && elt.hasAttribute('sse-connect')) {
+            connect(elt, 0);
+          } else if (elt.hasAttribute && elt.hasAttribute('sse-swap')) {
+            const parent = elt.closest('[sse-connect]');
+            const source = parent && api.getInternalData(parent).sseEventSource;
+            if (source) {
+              listen(source, elt);
+            }
+          }
+      }
+    }
+  });
+
+  // connect opens the stream for elt and starts every swap under it listening.
+  // The browser retries a dropped stream by itself; one it gives up on is
+  // reopened here with a growing delay.
+  function connect(elt, attempt) {
+    const internal = api.getInternalData(elt);
+    if (internal.sseEventSource && internal.sseEventSource.readyState !== EventSource.CLOSED) {
+      return;
+    }
+
+    const source = new EventSource(elt.getAttribute('sse-connect'));
+    internal.sseEventSource = source;
+
+    source.onopen = function() {
+      attempt = 0;
+      api.triggerEvent(elt, 'htmx:sseOpen', { source: source });
+    };
+    source.onerror = function(err) {
+      api.triggerErrorEvent(elt, 'htmx:sseError', { error: err, source: source });
+      if (source.readyState === EventSource.CLOSED && document.body.contains(elt)) {
+        const delay = Math.min(1000 * Math.pow(2, attempt), 64000);
+        internal.sseRetry = setTimeout(function() {
+          connect(elt, attempt + 1);
+        }, delay);
+      }
+    };
+
+    const swaps = Array.from(elt.querySelectorAll('[sse-swap]'));
+    if (elt.hasAttribute('sse-swap')) {
+      swaps.unshift(elt);
+    }
+    swaps.forEach(function(swapElt) {
+      if (swapElt === elt || swapElt.parentElement.closest('[sse-connect]') === elt) {
+        listen(source, swapElt);
+      }
+    });
+  }
+
+  // listen swaps each named event from source into elt, once per source
+  function listen(source, elt) {
+    const internal = api.getInternalData(elt);
+    if (internal.sseSource === source) {
+      return;
+    }
+    internal.sseSource = source;
+
+    elt.getAttribute('sse-swap').split(',').forEach(function(eventName) {
+      eventName = eventName.trim();
+      const listener = function(event) {
+        if (!document.body.contains(elt)) {
+          source.removeEventListener(eventName, listener);
+          return;
+        }
+        if (!api.triggerEvent(elt, 'htmx:sseBeforeMessage', event)) {
+          return;
+        }
+        htmx.swap(elt, event.data, api.getSwapSpecification(elt));
+        api.triggerEvent(elt, 'htmx:sseMessage', event);
+      };
+      source.addEventListener(eventName, listener);
+    });
+  }
+})();
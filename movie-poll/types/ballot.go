@@ -0,0 +1,28 @@
+package types
+
+// RunoffCount is a movie's tally in one instant-runoff round
+type RunoffCount struct {
+	MovieID int    `json:"movie_id"`
+	Title   string `json:"title"`
+	Votes   int    `json:"votes"`
+}
+
+// RunoffRound represents one counting round of an instant-runoff tally
+type RunoffRound struct {
+	Number     int           `json:"number"`
+	Counts     []RunoffCount `json:"counts"`
+	Eliminated []RunoffCount `json:"eliminated"`
+	Exhausted  int           `json:"exhausted"`
+}
+
+// RunoffResult represents the outcome of an instant-runoff tally
+type RunoffResult struct {
+	TotalBallots int           `json:"total_ballots"`
+	Rounds       []RunoffRound `json:"rounds"`
+	Winners      []RunoffCount `json:"winners"` // more than one means an unbreakable tie
+}
+
+// HasWinner reports whether the tally produced a single winner
+func (r *RunoffResult) HasWinner() bool {
+	return len(r.Winners) == 1
+}
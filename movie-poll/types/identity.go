@@ -0,0 +1,62 @@
+package types
+
+import (
+	"errors"
+	"strings"
+)
+
+// Identity is one voter as the votes see them: a name on a device
+type Identity struct {
+	UserName string `json:"user_name"`
+	DeviceID string `json:"device_id"`
+}
+
+// String encodes the identity for a form field, device first since device
+// IDs never contain the separator
+func (i Identity) String() string {
+	return i.DeviceID + "|" + i.UserName
+}
+
+// ParseIdentity reverses Identity.String
+func ParseIdentity(value string) (Identity, error) {
+	deviceID, userName, ok := strings.Cut(value, "|")
+	if !ok || deviceID == "" || userName == "" {
+		return Identity{}, errors.New("malformed identity " + value)
+	}
+	return Identity{UserName: userName, DeviceID: deviceID}, nil
+}
+
+// MergeKey picks out one movie in one round, where a voter holds one vote
+type MergeKey struct {
+	MovieID int `json:"movie_id"`
+	RoundID int `json:"round_id"` // 0 for votes cast outside any round
+}
+
+// MergeConflict is a movie more than one of the identities being merged
+// voted on in the same round, so only one vote can be kept
+type MergeConflict struct {
+	Key   MergeKey `json:"key"`
+	Title string   `json:"title"`
+	Votes []Vote   `json:"votes"`
+}
+
+// IdentitySummary is an identity with how many votes it holds
+type IdentitySummary struct {
+	Identity  Identity `json:"identity"`
+	VoteCount int      `json:"vote_count"`
+}
+
+// MergePreview shows what merging some identities involves
+type MergePreview struct {
+	Identities []IdentitySummary `json:"identities"`
+	Conflicts  []MergeConflict   `json:"conflicts"`
+}
+
+// MergeRequest describes an identity merge
+type MergeRequest struct {
+	Identities    []Identity
+	CanonicalName string
+	DeviceID      string           // device the merged votes move to, one of the identities'
+	Keep          map[MergeKey]int // ID of the vote to keep for each conflict, the newest if left out
+	AdminUser     string
+}
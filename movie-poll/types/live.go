@@ -0,0 +1,19 @@
+package types
+
+// Live vote stages, in the order a session moves through them
+const (
+	LiveStageClosed = "closed" // no session running
+	LiveStageLobby  = "lobby"  // participants gathering
+	LiveStageMovie  = "movie"  // everyone voting on the movie on screen
+	LiveStageReveal = "reveal" // showing how the room voted on it
+	LiveStageDone   = "done"   // every movie shown
+)
+
+// LiveSessionState is a snapshot of a host-driven live vote
+type LiveSessionState struct {
+	Stage      string `json:"stage"`
+	MovieIndex int    `json:"movie_index"` // 0-based position of Movie
+	MovieCount int    `json:"movie_count"`
+	Movie      *Movie `json:"movie,omitempty"` // the movie on screen during movie and reveal
+	RoundID    uint   `json:"round_id"`        // round the votes land in, 0 for none
+}
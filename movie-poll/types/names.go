@@ -0,0 +1,28 @@
+package types
+
+// Why a name was suggested as a match
+const (
+	NameMatchSame     = "same"     // only differs in case, accents or spacing
+	NameMatchNickname = "nickname" // e.g. Bill for William
+	NameMatchSound    = "sound"    // spelt differently but sounds alike
+	NameMatchSpelling = "spelling" // a typo or close spelling
+)
+
+// NameMatch is an existing name ranked against one being entered
+type NameMatch struct {
+	Name   string  `json:"name"`
+	Score  float64 `json:"score"` // 0 (nothing alike) to 1 (the same name)
+	Reason string  `json:"reason"`
+}
+
+// NameCheck is the answer to whether a name being entered is already known,
+// either on this device or anywhere in the poll
+type NameCheck struct {
+	HasExisting  bool        `json:"hasExisting"`
+	DeviceNames  []string    `json:"deviceNames,omitempty"`
+	ClosestMatch string      `json:"closestMatch,omitempty"`
+	Similarity   float64     `json:"similarity,omitempty"`
+	HasSimilar   bool        `json:"hasSimilar"`
+	SimilarNames []string    `json:"similarNames,omitempty"`
+	Matches      []NameMatch `json:"matches"` // best first
+}
@@ -0,0 +1,37 @@
+package types
+
+// AppealTrendPoint is a movie's standing in one appeal snapshot
+type AppealTrendPoint struct {
+	TakenAt     int64   `json:"taken_at"`
+	Rank        int     `json:"rank"`
+	AppealScore float64 `json:"appeal_score"`
+	TotalVotes  int     `json:"total_votes"`
+}
+
+// AppealTrend follows one movie through the kept snapshots, oldest first
+type AppealTrend struct {
+	MovieID  int                `json:"movie_id"`
+	Title    string             `json:"title"`
+	Strategy string             `json:"strategy"`
+	Points   []AppealTrendPoint `json:"points"`
+}
+
+// AppealMover is a movie whose rank changed since the last snapshot
+type AppealMover struct {
+	MovieID      int     `json:"movie_id"`
+	Title        string  `json:"title"`
+	Rank         int     `json:"rank"`
+	PreviousRank int     `json:"previous_rank"` // 0 if it wasn't ranked then
+	ScoreChange  float64 `json:"score_change"`
+}
+
+// Climb is how many places the movie moved up; negative when it fell
+func (m AppealMover) Climb() int {
+	return m.PreviousRank - m.Rank
+}
+
+// AppealMovers lists the biggest rank changes since a snapshot
+type AppealMovers struct {
+	Since  int64         `json:"since"`
+	Movers []AppealMover `json:"movers"`
+}
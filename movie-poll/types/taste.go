@@ -0,0 +1,37 @@
+package types
+
+// TasteUser identifies a voter in a taste matrix
+type TasteUser struct {
+	UserName string `json:"user_name"`
+	DeviceID string `json:"device_id"`
+}
+
+// TasteMatrix holds how much each pair of voters agrees, from 0 (opposite
+// vibes on everything) to 1 (identical vibes). Agreement[i][j] is nil on the
+// diagonal and for pairs with fewer than MinShared movies in common.
+type TasteMatrix struct {
+	Users     []TasteUser  `json:"users"`
+	Agreement [][]*float64 `json:"agreement"`
+	Shared    [][]int      `json:"shared"` // movies both voters rated
+	MinShared int          `json:"min_shared"`
+}
+
+// TasteTwin is the voter whose vibes are closest to someone's
+type TasteTwin struct {
+	UserName  string  `json:"user_name"`
+	Agreement float64 `json:"agreement"`
+	Shared    int     `json:"shared"`
+}
+
+// VibePrediction is how much a voter is expected to enjoy a movie they
+// haven't seen, judged by similar voters who have
+type VibePrediction struct {
+	MovieID    uint    `json:"movie_id"`
+	Enthusiasm float64 `json:"enthusiasm"` // 0 (Meh) to 1 (Rewatch)
+	Neighbours int     `json:"neighbours"` // similar voters the guess is based on
+}
+
+// Score puts the prediction on a 0-10 scale
+func (p VibePrediction) Score() int {
+	return int(p.Enthusiasm*10 + 0.5)
+}
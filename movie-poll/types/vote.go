@@ -8,6 +8,16 @@ type Vote struct {
 	Vibe      int    `json:"vibe"`
 	Seen      bool   `json:"seen"`
 	DeviceID  string `json:"device_id"`
+	RoundID   int    `json:"round_id,omitempty"`
 	CreatedAt int64  `json:"created_at"`
 	UpdatedAt int64  `json:"updated_at"`
 }
+
+// BatchVoteResult reports the outcome of one vote in a batch submission
+type BatchVoteResult struct {
+	Index   int    `json:"index"`
+	MovieID int    `json:"movie_id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+	Vote    *Vote  `json:"vote,omitempty"`
+}
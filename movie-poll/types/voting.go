@@ -2,21 +2,53 @@ package types
 
 // VotingSummary represents the summary of voting results
 type VotingSummary struct {
-	MovieID         int     `json:"movie_id"`
-	Title           string  `json:"title"`
-	Year            *int    `json:"year,omitempty"`
-	Overview        *string `json:"overview,omitempty"`
-	PosterPath      *string `json:"poster_path,omitempty"`
-	ReleaseDate     *string `json:"release_date,omitempty"`
-	VoteCount       int     `json:"vote_count"`
-	AverageVibe     float64 `json:"average_vibe"`
-	AppealScore     float64 `json:"appeal_score"`
-	SeenCount       int     `json:"seen_count"`
-	NotSeenCount    int     `json:"not_seen_count"`
-	VisibilityRatio float64 `json:"visibility_ratio"`
-	TotalVotes      int     `json:"total_votes"`
-	UniqueVoters    int     `json:"unique_voters"`
-	CalculatedAt    int64   `json:"calculated_at"`
+	MovieID         int           `json:"movie_id"`
+	Title           string        `json:"title"`
+	Year            *int          `json:"year,omitempty"`
+	Overview        *string       `json:"overview,omitempty"`
+	PosterPath      *string       `json:"poster_path,omitempty"`
+	ReleaseDate     *string       `json:"release_date,omitempty"`
+	VoteCount       int           `json:"vote_count"`
+	AverageVibe     float64       `json:"average_vibe"`
+	MedianVibe      float64       `json:"median_vibe"`
+	Vibes           VibeHistogram `json:"vibes"`
+	AppealScore     float64       `json:"appeal_score"`
+	Strategy        string        `json:"strategy"` // the appeal strategy that produced AppealScore
+	SeenCount       int           `json:"seen_count"`
+	NotSeenCount    int           `json:"not_seen_count"`
+	VisibilityRatio float64       `json:"visibility_ratio"`
+	TotalVotes      int           `json:"total_votes"`
+	UniqueVoters    int           `json:"unique_voters"`
+	VetoCount       int           `json:"veto_count"`
+	Polarization    float64       `json:"polarization"` // 0 when everyone agrees, 1 when split evenly between love and meh
+	CalculatedAt    int64         `json:"calculated_at"`
+	ScreenedAt      int64         `json:"screened_at,omitempty"` // last time the tavern watched it, 0 if never
+}
+
+// VibeHistogram counts a movie's votes per vibe, split by whether the voter
+// has seen it. Index 0 holds vibe 1.
+type VibeHistogram struct {
+	Seen    [6]int `json:"seen"`
+	NotSeen [6]int `json:"not_seen"`
+}
+
+// Count returns the number of votes with the given vibe
+func (h VibeHistogram) Count(vibe int) int {
+	if vibe < 1 || vibe > len(h.Seen) {
+		return 0
+	}
+	return h.Seen[vibe-1] + h.NotSeen[vibe-1]
+}
+
+// Max returns the largest per-vibe count, for scaling a chart
+func (h VibeHistogram) Max() int {
+	max := 0
+	for vibe := 1; vibe <= len(h.Seen); vibe++ {
+		if count := h.Count(vibe); count > max {
+			max = count
+		}
+	}
+	return max
 }
 
 // VotingStats represents overall voting statistics
@@ -29,3 +61,62 @@ type VotingStats struct {
 	MostVotedMovie     string  `json:"most_voted_movie"`
 	MostVotedCount     int     `json:"most_voted_count"`
 }
+
+// ResultsOptions selects which votes a results summary counts
+type ResultsOptions struct {
+	RoundID uint // poll round, 0 for votes cast outside rounds
+	EventID uint // if set, only count votes from people going to this event
+}
+
+// DivisivePolarization is the polarization above which a movie counts as divisive
+const DivisivePolarization = 0.5
+
+// Results list sort keys
+const (
+	ResultsSortAppeal     = "appeal"
+	ResultsSortVotes      = "votes"
+	ResultsSortVibe       = "vibe"
+	ResultsSortVisibility = "visibility"
+	ResultsSortDivisive   = "divisive"
+)
+
+// ResultsQuery sorts, filters and pages a results list
+type ResultsQuery struct {
+	Sort             string  // one of the ResultsSort keys, appeal by default
+	Order            string  // "asc" or "desc"; empty uses the sort key's natural order
+	Year             int     // 0 for any year
+	MinSeenRatio     float64 // share of voters who have seen it, 0-1
+	MaxSeenRatio     float64
+	MinParticipation int     // minimum number of votes
+	MinPolarization  float64 // 0-1
+	MaxPolarization  float64
+	Page             int // 1-based
+	PageSize         int
+}
+
+// ResultsList is one page of a results list
+type ResultsList struct {
+	Results      []VotingSummary `json:"results"`
+	Page         int             `json:"page"`
+	PageSize     int             `json:"page_size"`
+	TotalResults int             `json:"total_results"`
+	TotalPages   int             `json:"total_pages"`
+}
+
+// ResultsExport is everything a results export contains
+type ResultsExport struct {
+	GeneratedAt int64           `json:"generated_at"`
+	RoundName   string          `json:"round_name,omitempty"`
+	EventName   string          `json:"event_name,omitempty"`
+	Strategy    string          `json:"strategy"`
+	Stats       VotingStats     `json:"stats"`
+	Results     []VotingSummary `json:"results"`
+	Votes       *VoteMatrix     `json:"votes,omitempty"`
+}
+
+// VoteMatrix lays out each voter's vibe for each ranked movie
+type VoteMatrix struct {
+	Movies []string `json:"movies"` // column titles, in ranked order
+	Voters []string `json:"voters"`
+	Vibes  [][]int  `json:"vibes"` // [voter][movie], 0 where they didn't vote
+}